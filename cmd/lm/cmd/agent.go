@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+)
+
+// agentGracePeriod is how long an interrupted agent may take to exit
+// before its process group is killed
+const agentGracePeriod = 5 * time.Second
+
+// errInterrupted is returned when the user interrupted an interactive agent
+var errInterrupted = errors.New("interrupted")
+
+// runAgent runs the claude CLI with args in a process group of its own.
+//
+// An interactive agent gets the terminal: its process group is moved to
+// the foreground, so Ctrl-C reaches the agent rather than lm, and the
+// terminal is handed back when it exits. A non-interactive agent runs in
+// the background without stdin; Ctrl-C or SIGTERM cancel ctx, which
+// terminates the whole group, and kills it after agentGracePeriod.
+//
+// Callers must not hold the META lock while the agent runs: skills call
+// lm themselves ('lm item status', 'lm meta commit'), which takes the lock.
+// IDs a skill needs are reserved under the lock before, and settled after.
+//
+// Temporary worktrees the agent created and did not remove are cleaned
+// up when it fails or is interrupted.
+func runAgent(ctx context.Context, interactive bool, args ...string) error {
+	before := tempWorktreePaths(ctx)
+
+	cmd := exec.CommandContext(ctx, "claude", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	foreground := false
+	if interactive {
+		cmd.Stdin = os.Stdin
+		if isForegroundTerminal(os.Stdin) {
+			cmd.SysProcAttr.Foreground = true
+			cmd.SysProcAttr.Ctty = int(os.Stdin.Fd())
+			foreground = true
+		}
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = agentGracePeriod
+
+	err := cmd.Run()
+	if foreground {
+		reclaimTerminal(os.Stdin)
+	}
+	if cmd.Process != nil && (err != nil || ctx.Err() != nil) {
+		// Don't leave tool processes of the agent behind
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	case interruptedBySignal(err):
+		err = errInterrupted
+	}
+	if err != nil {
+		removeNewTempWorktrees(before)
+	}
+	return err
+}
+
+// interrupted reports whether err means the user interrupted lm or the
+// agent, rather than something failing
+func interrupted(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, errInterrupted)
+}
+
+// interruptedBySignal reports whether the agent was ended by SIGINT or SIGTERM
+func interruptedBySignal(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && (status.Signal() == syscall.SIGINT || status.Signal() == syscall.SIGTERM)
+}
+
+// isForegroundTerminal reports whether f is a terminal whose foreground
+// process group is the one of lm
+func isForegroundTerminal(f *os.File) bool {
+	var pgrp int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgrp)))
+	return errno == 0 && int(pgrp) == syscall.Getpgrp()
+}
+
+// reclaimTerminal moves lm's process group back to the foreground of the
+// terminal. lm is a background process at that point, so SIGTTOU, which
+// would stop it, is ignored meanwhile.
+func reclaimTerminal(f *os.File) {
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+
+	pgrp := int32(syscall.Getpgrp())
+	syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCSPGRP), uintptr(unsafe.Pointer(&pgrp)))
+}
+
+// tempWorktreePaths returns the temporary worktrees that exist right now
+func tempWorktreePaths(ctx context.Context) map[string]bool {
+	paths := make(map[string]bool)
+	worktrees, _ := meta.TempWorktrees(ctx, 0)
+	for _, wt := range worktrees {
+		paths[wt.Path] = true
+	}
+	return paths
+}
+
+// removeNewTempWorktrees removes the temporary worktrees that did not exist
+// before an agent ran. It runs after ctx may be done, so it uses its own.
+func removeNewTempWorktrees(before map[string]bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	worktrees, err := meta.TempWorktrees(ctx, 0)
+	if err != nil {
+		return
+	}
+	for _, wt := range worktrees {
+		if before[wt.Path] {
+			continue
+		}
+		if err := meta.RemoveTempWorktree(ctx, wt); err != nil {
+			printError(fmt.Sprintf("Failed to remove %s: %s", wt.Path, err))
+			continue
+		}
+		printInfo("Removed leftover worktree " + wt.Path)
+	}
+}
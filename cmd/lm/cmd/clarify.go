@@ -1,11 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/laddermoon/laddermoon/pkg/items"
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
 )
@@ -31,22 +31,29 @@ func init() {
 }
 
 func runClarify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check if we're in a git repository
-	_, err := meta.GetGitRoot()
+	_, err := meta.GetGitRoot(ctx)
 	if err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
 	// Check if initialized
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized.")
 		printInfo("Run 'lm init' to initialize.")
 		return meta.ErrNotInitialized
 	}
 
 	// Check if skills are installed
-	if !meta.SkillsInstalled() {
+	if !meta.SkillsInstalled(ctx) {
 		printError("LadderMoon skills are not installed.")
 		printInfo("Run 'lm init' to reinstall.")
 		return fmt.Errorf("skills not installed")
@@ -58,13 +65,16 @@ func runClarify(cmd *cobra.Command, args []string) error {
 
 		// Step 1: Criticize META to find issues
 		printInfo("Step 1: Analyzing META for clarity issues...")
-		if err := invokeCriticizeSkill(); err != nil {
+		if err := invokeCriticizeSkill(ctx, store); err != nil {
 			printError("Criticize failed: " + err.Error())
 			return err
 		}
 
 		// Step 2: Check if there are open questions
-		questions := findOpenQuestions()
+		questions, err := findOpenItems(store, items.TypeQuestion)
+		if err != nil {
+			return err
+		}
 		if len(questions) == 0 {
 			printSuccess("META is clear! No more questions to resolve.")
 			break
@@ -72,13 +82,15 @@ func runClarify(cmd *cobra.Command, args []string) error {
 
 		printInfo(fmt.Sprintf("Found %d open question(s):", len(questions)))
 		for i, q := range questions {
-			fmt.Printf("  %d. %s\n", i+1, q)
+			fmt.Printf("  %d. %s - %s\n", i+1, q.ID, q.Title())
 		}
 
 		// Step 3: Let user choose which to address
 		fmt.Print("\nEnter question number to clarify (or 'q' to quit, 'a' for all): ")
-		var choice string
-		fmt.Scanln(&choice)
+		choice, err := readLine(ctx)
+		if err != nil {
+			return err
+		}
 
 		if strings.ToLower(choice) == "q" {
 			printInfo("Exiting clarify loop.")
@@ -88,8 +100,11 @@ func runClarify(cmd *cobra.Command, args []string) error {
 		if strings.ToLower(choice) == "a" {
 			// Clarify all questions
 			for _, q := range questions {
-				printInfo("Clarifying: " + q)
-				if err := invokeClarifySkillForQuestion(q); err != nil {
+				printInfo("Clarifying: " + q.Path)
+				if err := invokeClarifySkillForQuestion(ctx, store, q.Path); err != nil {
+					if interrupted(err) {
+						return err
+					}
 					printError("Failed to clarify: " + err.Error())
 				}
 			}
@@ -98,8 +113,11 @@ func runClarify(cmd *cobra.Command, args []string) error {
 			var idx int
 			fmt.Sscanf(choice, "%d", &idx)
 			if idx >= 1 && idx <= len(questions) {
-				printInfo("Clarifying: " + questions[idx-1])
-				if err := invokeClarifySkillForQuestion(questions[idx-1]); err != nil {
+				printInfo("Clarifying: " + questions[idx-1].Path)
+				if err := invokeClarifySkillForQuestion(ctx, store, questions[idx-1].Path); err != nil {
+					if interrupted(err) {
+						return err
+					}
 					printError("Failed to clarify: " + err.Error())
 				}
 			} else {
@@ -117,47 +135,29 @@ func runClarify(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func invokeCriticizeSkill() error {
-	prompt := "Use the laddermoon-criticize skill to analyze META for clarity and completeness, then file Questions for areas that need clarification."
+func invokeCriticizeSkill(ctx context.Context, store meta.MetaStore) error {
+	ids, err := reserveItemIDs(ctx, store, items.TypeQuestion)
+	if err != nil {
+		return err
+	}
+	defer settleItems(ctx, store, items.TypeQuestion, ids)
 
-	cmd := exec.Command("claude", "-p", prompt, "--dangerously-skip-permissions")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	prompt := "Use the laddermoon-criticize skill to analyze META for clarity and completeness, then file Questions for areas that need clarification.\n\n" + describeItemIDs(items.TypeQuestion, ids)
 
-	return cmd.Run()
+	return runAgent(ctx, false, "-p", prompt, "--dangerously-skip-permissions")
 }
 
-func findOpenQuestions() []string {
-	files, err := meta.GetMetaFileList()
+func invokeClarifySkillForQuestion(ctx context.Context, store meta.MetaStore, questionFile string) error {
+	// A question the code can't answer becomes an issue
+	ids, err := reserveItemIDs(ctx, store, items.TypeIssue)
 	if err != nil {
-		return nil
-	}
-
-	var questions []string
-	for _, f := range files {
-		if !strings.HasPrefix(f, "Questions/") {
-			continue
-		}
-		content, err := meta.ReadFile(f)
-		if err != nil || content == "" {
-			continue
-		}
-		if strings.Contains(content, "**Status**: Open") {
-			questions = append(questions, f)
-		}
+		return err
 	}
-	return questions
-}
+	defer settleItems(ctx, store, items.TypeIssue, ids)
 
-func invokeClarifySkillForQuestion(questionFile string) error {
-	prompt := fmt.Sprintf("Use the laddermoon-clarify skill to resolve this question: %s\n\nAnalyze the codebase first. Only ask me if you cannot find the answer in the code.", questionFile)
+	prompt := fmt.Sprintf("Use the laddermoon-clarify skill to resolve this question: %s\n\nAnalyze the codebase first. Only ask me if you cannot find the answer in the code.\n\n%s",
+		questionFile, describeItemIDs(items.TypeIssue, ids))
 
 	// This skill may need user interaction
-	cmd := exec.Command("claude", prompt)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return runAgent(ctx, true, prompt)
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+// commentHint is appended to the comment opened in the editor and removed
+// from what is saved
+const commentHint = "\n<!-- Write your comment on %s above. Leave it empty to cancel. -->\n"
+
+var commentCmd = &cobra.Command{
+	Use:   "comment <item> [text]",
+	Short: "Add a comment to an item's discussion",
+	Long: `Append a comment to the Discussion section of an Issue, Question,
+Proposal or Task, with your git user and the time. Without text the
+comment is written in $EDITOR.
+
+Skills that work from an item read its discussion, and a Task created from
+an Issue or Proposal takes over its discussion, so a comment such as
+"valid, but fix it in the parser" reaches the code and review steps.
+
+Example:
+  lm comment issue-001 "Valid, but the fix belongs in the parser"
+  lm comment task-002             # Write the comment in $EDITOR`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runComment,
+}
+
+func init() {
+	rootCmd.AddCommand(commentCmd)
+}
+
+func runComment(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ref := args[0]
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	// Look the item up before opening the editor, so a typo doesn't cost
+	// the comment
+	item, err := items.Find(store, ref)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read %s: %s", ref, err))
+		return err
+	}
+	if item == nil {
+		printError(fmt.Sprintf("Item not found: %s", ref))
+		return meta.ErrNotFound
+	}
+
+	text := strings.Join(args[1:], " ")
+	if text == "" {
+		hint := fmt.Sprintf(commentHint, item.ID)
+		edited, err := editText(hint)
+		if err != nil {
+			printError("Editor failed: " + err.Error())
+			return err
+		}
+		text = strings.Replace(edited, strings.TrimSpace(hint), "", 1)
+	}
+	if strings.TrimSpace(text) == "" {
+		printInfo("Empty comment, nothing saved.")
+		return nil
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	// The item may have changed while the comment was written
+	item, err = items.Load(store, item.Path)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read %s: %s", ref, err))
+		return err
+	}
+
+	if err := meta.Comment(store, item, commentAuthor(ctx), text); err != nil {
+		printError(fmt.Sprintf("Failed to comment on %s: %s", item.ID, err))
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Comment added to %s.", item.ID))
+	return nil
+}
+
+// commentAuthor returns the git identity to sign a comment with, falling
+// back to the email when no user name is configured, so the comment's
+// heading never lacks an author
+func commentAuthor(ctx context.Context) string {
+	if user := meta.GetGitUser(ctx); user != "" {
+		return user
+	}
+	if email := meta.GetGitEmail(ctx); email != "" {
+		return email
+	}
+	return "unknown"
+}
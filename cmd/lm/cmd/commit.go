@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metaCommitMessage  string
+	metaCommitTrailers []string
+)
+
+var metaCommitCmd = &cobra.Command{
+	Use:   "commit <worktree>",
+	Short: "Commit META changes made in a worktree of laddermoon-meta",
+	Long: `Commit the changes made to the current branch's META directory in a
+worktree of the laddermoon-meta branch.
+
+Skills edit META in a temporary worktree and commit through this command
+instead of running git commit there. It takes the META lock and applies
+the changes onto the current tip of laddermoon-meta, so skills and lm
+commands running at the same time don't overwrite each other's commits.
+If a changed file was also changed on laddermoon-meta since the worktree
+was checked out, nothing is committed.
+
+The LM-Code-Commit and LM-Session trailers are filled in when not given.
+
+Example:
+  tmpdir=$(mktemp -d .lm-tmp-XXXXXX)
+  git worktree add --detach "$tmpdir" laddermoon-meta
+  # edit files under "$tmpdir/<branch directory>"
+  lm meta commit "$tmpdir" -m "Feed #3: Record the API limits" \
+    --trailer "LM-Operation: feed" --trailer "LM-Feed-ID: 3"
+  git worktree remove --force "$tmpdir"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMetaCommit,
+}
+
+func init() {
+	metaCommitCmd.Flags().StringVarP(&metaCommitMessage, "message", "m", "", "Commit message")
+	metaCommitCmd.Flags().StringArrayVar(&metaCommitTrailers, "trailer", nil, "LM trailer as 'Key: value' (repeatable)")
+	metaCmd.AddCommand(metaCommitCmd)
+}
+
+func runMetaCommit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	store, err := requireShadowStore(ctx)
+	if err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	message := metaCommitMessage
+	if strings.TrimSpace(message) == "" {
+		printError("A commit message is required, pass it with -m.")
+		return fmt.Errorf("empty commit message")
+	}
+	if len(metaCommitTrailers) > 0 {
+		message = strings.TrimRight(message, "\n") + "\n\n" + strings.Join(metaCommitTrailers, "\n")
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	changed, err := store.CommitWorktree(dir, message)
+	switch {
+	case errors.Is(err, meta.ErrWorktreeConflict):
+		printError("Not committed: " + err.Error())
+		printInfo("Check out a new worktree and redo the changes on the current META.")
+		return err
+	case err != nil:
+		printError("Failed to commit META changes: " + err.Error())
+		return err
+	case len(changed) == 0:
+		printInfo("No META changes to commit.")
+		return nil
+	}
+
+	printSuccess(fmt.Sprintf("Committed %d META file(s):", len(changed)))
+	for _, f := range changed {
+		printInfo("  " + f)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var issuesDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find issues that are likely duplicates of each other",
+	Long: `Compare the open and rejected issues by the words of their titles and
+bodies, and suggest which ones to merge. The comparison runs locally
+(TF-IDF cosine similarity), nothing is sent anywhere and nothing is
+changed: close the duplicates with 'lm item status'.
+
+Example:
+  lm issues dedupe
+  lm issues dedupe --threshold 0.3   # Also show looser matches`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.NoArgs(cmd, args); err != nil {
+			return err
+		}
+		if dedupeThreshold <= 0 || dedupeThreshold > 1 {
+			return fmt.Errorf("--threshold must be above 0 and at most 1")
+		}
+		return nil
+	},
+	RunE: runIssuesDedupe,
+}
+
+var dedupeThreshold float64
+
+func init() {
+	issuesDedupeCmd.Flags().Float64Var(&dedupeThreshold, "threshold", items.DuplicateThreshold, "Similarity from 0 to 1 from which issues count as duplicates")
+	issuesCmd.AddCommand(issuesDedupeCmd)
+}
+
+func runIssuesDedupe(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	candidates, err := duplicateCandidates(store, items.TypeIssue)
+	if err != nil {
+		printError("Failed to load issues: " + err.Error())
+		return err
+	}
+
+	groups := items.NewCorpus(candidates).Duplicates(dedupeThreshold)
+	if len(groups) == 0 {
+		printSuccess(fmt.Sprintf("No likely duplicates among %d issue(s).", len(candidates)))
+		return nil
+	}
+
+	for n, group := range groups {
+		fmt.Printf("Group %d:\n", n+1)
+		for _, m := range group {
+			fmt.Printf("  [%s] %s - %s (%.0f%% similar)\n", m.Item.Status, m.Item.ID, m.Item.Title(), m.Score*100)
+		}
+		keep := keeperOf(group)
+		for _, m := range group {
+			if m.Item != keep && !m.Item.IsClosed() {
+				fmt.Printf("  Merge: lm item status %s %s --reason \"duplicate of %s\"\n", m.Item.ID, closeAsDuplicate(m.Item), keep.ID)
+			}
+		}
+		fmt.Println()
+	}
+	printInfo(fmt.Sprintf("%d group(s) of likely duplicates among %d issue(s).", len(groups), len(candidates)))
+	return nil
+}
+
+// duplicateCandidates returns the items of a type a new item may repeat:
+// those not closed yet, and the rejected ones, so that rejected ideas are
+// not filed again
+func duplicateCandidates(store meta.MetaStore, typ items.Type) ([]*items.Item, error) {
+	all, err := items.LoadAll(store, typ)
+	if err = skipBrokenItems(err); err != nil {
+		return nil, err
+	}
+	var candidates []*items.Item
+	for _, item := range all {
+		if !item.IsClosed() || item.Status == items.StatusRejected {
+			candidates = append(candidates, item)
+		}
+	}
+	return candidates, nil
+}
+
+// keeperOf returns the item of a duplicate group the others merge into:
+// the one furthest along, such as one that already has a task, and of
+// those the oldest
+func keeperOf(group []items.Match) *items.Item {
+	progress := func(item *items.Item) int {
+		switch {
+		case item.IsClosed():
+			return 0
+		case item.Status == items.StatusTaskCreated:
+			return 3
+		case item.Status == items.StatusConfirmed:
+			return 2
+		}
+		return 1
+	}
+	keep := group[0].Item
+	for _, m := range group[1:] {
+		if progress(m.Item) > progress(keep) {
+			keep = m.Item
+		}
+	}
+	return keep
+}
+
+// closeAsDuplicate returns the status a duplicate is closed with: rejected,
+// or wont-fix once the item has a task
+func closeAsDuplicate(item *items.Item) string {
+	for _, next := range item.NextStatuses() {
+		if next == items.StatusRejected {
+			return next
+		}
+	}
+	return items.StatusWontFix
+}
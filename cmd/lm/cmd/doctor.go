@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/laddermoon/laddermoon/skills"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the META system for broken states",
+	Long: `Diagnose states that make other commands fail:
+
+  - .next_feed_id lower than the highest Feed #N in UserFeed.log
+  - .sync_state pointing to a commit that no longer exists
+  - item indexes that disagree with the item files
+  - leftover .lm-tmp-* directories or worktrees of interrupted skills
+  - a stale .lm.lock of a process that exited
+  - skills missing from .claude/skills
+  - a missing 'claude' binary
+  - a current branch without META
+
+With --fix every problem that can be repaired is repaired. lm doctor exits
+with code 1 while problems remain.
+
+Example:
+  lm doctor
+  lm doctor --fix`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the problems found")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorProblem is a broken state found by a doctor check
+type doctorProblem struct {
+	desc string
+	// hint tells how to repair the problem by hand, when fix is nil
+	hint string
+	// fix repairs the problem and returns what it did
+	fix func() (string, error)
+}
+
+// doctorCheck inspects one part of the META system. It returns nil when
+// everything is fine.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context, store meta.MetaStore) (*doctorProblem, error)
+}
+
+// doctorChecks run in order; the branch check comes first since the
+// store checks need META for the current branch
+var doctorChecks = []doctorCheck{
+	{"Branch META", checkBranchMeta},
+	{"META lock", checkStaleLock},
+	{"Feed counter", checkFeedCounter},
+	{"Sync state", checkSyncState},
+	{"Item indexes", checkItemIndexes},
+	{"Temporary worktrees", checkTempWorktrees},
+	{"Skills", checkSkills},
+	{"Claude CLI", checkClaude},
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	remaining, fixed := 0, 0
+	for _, check := range doctorChecks {
+		problem, err := check.run(ctx, store)
+		if err != nil {
+			printError(fmt.Sprintf("%s: check failed: %s", check.name, err))
+			remaining++
+			continue
+		}
+		if problem == nil {
+			printInfo("✓ " + check.name)
+			continue
+		}
+
+		printInfo(fmt.Sprintf("✗ %s: %s", check.name, problem.desc))
+		if problem.fix == nil {
+			printInfo("  " + problem.hint)
+			remaining++
+			continue
+		}
+		if !doctorFix {
+			remaining++
+			continue
+		}
+		done, err := problem.fix()
+		if err != nil {
+			printError(fmt.Sprintf("%s: failed to fix: %s", check.name, err))
+			remaining++
+			continue
+		}
+		printInfo("  Fixed: " + done)
+		fixed++
+	}
+
+	fmt.Println()
+	if remaining == 0 {
+		if fixed > 0 {
+			printSuccess(fmt.Sprintf("Fixed %d problem(s).", fixed))
+		} else {
+			printSuccess("No problems found.")
+		}
+		return nil
+	}
+	if !doctorFix {
+		printInfo("Run 'lm doctor --fix' to repair what can be repaired.")
+	}
+	printError(fmt.Sprintf("%d problem(s) remain.", remaining))
+	return fmt.Errorf("%d problem(s) remain", remaining)
+}
+
+// checkBranchMeta checks that the current branch has META
+func checkBranchMeta(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	if store.Initialized() {
+		return nil, nil
+	}
+	branch, err := meta.GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &doctorProblem{
+		desc: fmt.Sprintf("branch %s has no META in %s", branch, store.Location()),
+		fix: func() (string, error) {
+			fork, err := forkParentMeta(ctx, store)
+			if err != nil {
+				return "", err
+			}
+			if fork != nil {
+				return "forked META from branch " + fork.Parent, nil
+			}
+			if err := store.Init(); err != nil {
+				return "", err
+			}
+			return "created empty META", nil
+		},
+	}, nil
+}
+
+// checkStaleLock checks for a lock file left behind by a process that exited
+func checkStaleLock(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	holder, err := meta.StaleLockHolder(ctx)
+	if err != nil || holder == nil {
+		return nil, err
+	}
+	return &doctorProblem{
+		desc: "stale lock of " + holder.Describe(),
+		fix: func() (string, error) {
+			if _, err := meta.Unlock(ctx, true); err != nil {
+				return "", err
+			}
+			return "cleared the lock", nil
+		},
+	}, nil
+}
+
+// checkFeedCounter checks that .next_feed_id is beyond every recorded feed
+func checkFeedCounter(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	if !store.Initialized() {
+		return nil, nil
+	}
+	highest, err := meta.HighestFeedID(store)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := meta.GetNextFeedID(store)
+	desc := ""
+	switch {
+	case errors.Is(err, meta.ErrCorrupt):
+		desc = err.Error()
+	case err != nil:
+		return nil, err
+	case next <= highest:
+		desc = fmt.Sprintf("%s is %d but UserFeed.log already has Feed #%d", meta.FeedIDFile, next, highest)
+	default:
+		return nil, nil
+	}
+
+	return &doctorProblem{
+		desc: desc,
+		fix: func() (string, error) {
+			lock, err := acquireMetaLock(ctx)
+			if err != nil {
+				return "", err
+			}
+			defer lock.Release()
+			if err := meta.SetNextFeedID(store, highest+1); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("set %s to %d", meta.FeedIDFile, highest+1), nil
+		},
+	}, nil
+}
+
+// checkItemIndexes checks that every item index agrees with the item files
+func checkItemIndexes(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	if !store.Initialized() {
+		return nil, nil
+	}
+	stale, err := meta.StaleIndexes(store)
+	if err != nil || len(stale) == 0 {
+		return nil, err
+	}
+
+	names := make([]string, len(stale))
+	for n, typ := range stale {
+		names[n] = meta.IndexFile(typ)
+	}
+	return &doctorProblem{
+		desc: "indexes out of date with the item files: " + strings.Join(names, ", "),
+		fix: func() (string, error) {
+			lock, err := acquireMetaLock(ctx)
+			if err != nil {
+				return "", err
+			}
+			defer lock.Release()
+			if _, err := meta.RebuildIndexes(store, stale...); err != nil {
+				return "", err
+			}
+			return "rebuilt " + strings.Join(names, ", "), nil
+		},
+	}, nil
+}
+
+// checkSyncState checks that the last synced commit still exists
+func checkSyncState(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	if !store.Initialized() {
+		return nil, nil
+	}
+	synced, err := meta.GetSyncedCommitID(store)
+	if err != nil {
+		return nil, err
+	}
+	if synced == "" || meta.CommitExists(ctx, synced) {
+		return nil, nil
+	}
+
+	return &doctorProblem{
+		desc: fmt.Sprintf("%s points to commit %s which no longer exists", meta.SyncStateFile, shortCommit(synced)),
+		fix: func() (string, error) {
+			lock, err := acquireMetaLock(ctx)
+			if err != nil {
+				return "", err
+			}
+			defer lock.Release()
+			if err := meta.ClearSyncedCommitID(store); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("cleared %s, the next 'lm sync' starts over", meta.SyncStateFile), nil
+		},
+	}, nil
+}
+
+// checkTempWorktrees checks for worktrees left behind by interrupted skills
+func checkTempWorktrees(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	worktrees, err := meta.TempWorktrees(ctx, meta.TempWorktreeMinAge)
+	if err != nil || len(worktrees) == 0 {
+		return nil, err
+	}
+
+	var paths []string
+	for _, wt := range worktrees {
+		paths = append(paths, wt.Path)
+	}
+	return &doctorProblem{
+		desc: fmt.Sprintf("%d leftover temporary worktree(s): %s", len(worktrees), strings.Join(paths, ", ")),
+		fix: func() (string, error) {
+			for _, wt := range worktrees {
+				if err := meta.RemoveTempWorktree(ctx, wt); err != nil {
+					return "", fmt.Errorf("failed to remove %s: %w", wt.Path, err)
+				}
+			}
+			return fmt.Sprintf("removed %d worktree(s)", len(worktrees)), nil
+		},
+	}, nil
+}
+
+// checkSkills checks that every skill is installed in .claude/skills
+func checkSkills(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	missing, err := meta.MissingSkills(ctx, skills.SkillNames)
+	if err != nil || len(missing) == 0 {
+		return nil, err
+	}
+	return &doctorProblem{
+		desc: "missing from .claude/skills: " + strings.Join(missing, ", "),
+		fix: func() (string, error) {
+			if err := meta.InstallSkills(ctx, skills.SkillsFS, missing); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("installed %d skill(s)", len(missing)), nil
+		},
+	}, nil
+}
+
+// checkClaude checks that the claude binary skills run with is on PATH
+func checkClaude(ctx context.Context, store meta.MetaStore) (*doctorProblem, error) {
+	if _, err := exec.LookPath("claude"); err == nil {
+		return nil, nil
+	}
+	return &doctorProblem{
+		desc: "'claude' was not found in PATH",
+		hint: "Install the Claude Code CLI and make sure 'claude' is on your PATH.",
+	}, nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+)
+
+// Exit codes of lm, so scripts can tell failures apart
+const (
+	ExitOK             = 0
+	ExitFailure        = 1 // Any other error
+	ExitUsage          = 2 // Unknown command, bad flags or arguments, disallowed status change
+	ExitNotGitRepo     = 3
+	ExitNotInitialized = 4
+	ExitDetachedHead   = 5
+	ExitNotFound       = 6   // A META file or item doesn't exist
+	ExitCorrupt        = 7   // A META file can't be parsed
+	ExitGit            = 8   // A git command failed
+	ExitLocked         = 9   // The META lock is held by another process
+	ExitInterrupted    = 130 // Interrupted by SIGINT or SIGTERM, as shells report 128+SIGINT
+)
+
+var (
+	// errorReported is set once a command has printed its own error message
+	errorReported bool
+	// commandRan is set once a command's flags and arguments were accepted
+	commandRan bool
+)
+
+// ExitCode returns the exit code lm exits with for an error
+func ExitCode(err error) int {
+	var gitErr *meta.GitError
+	switch {
+	case err == nil:
+		return ExitOK
+	case interrupted(err):
+		return ExitInterrupted
+	case !commandRan, errors.Is(err, items.ErrInvalidTransition),
+		errors.Is(err, items.ErrDependencyCycle), errors.Is(err, items.ErrNotTask),
+		errors.Is(err, items.ErrTaskBlocked):
+		return ExitUsage
+	case errors.Is(err, meta.ErrNotGitRepo):
+		return ExitNotGitRepo
+	case errors.Is(err, meta.ErrNotInitialized):
+		return ExitNotInitialized
+	case errors.Is(err, meta.ErrDetachedHead):
+		return ExitDetachedHead
+	case errors.Is(err, meta.ErrLockHeld), errors.Is(err, meta.ErrLockHolderUp):
+		return ExitLocked
+	case errors.Is(err, meta.ErrCorrupt):
+		return ExitCorrupt
+	case errors.Is(err, meta.ErrNotFound):
+		return ExitNotFound
+	case errors.As(err, &gitErr):
+		return ExitGit
+	}
+	return ExitFailure
+}
+
+// describeError returns the message shown for an error no command printed
+func describeError(err error) string {
+	var gitErr *meta.GitError
+	switch {
+	case interrupted(err):
+		return "Interrupted."
+	case errors.Is(err, meta.ErrNotGitRepo):
+		return "This command must be run inside a Git repository."
+	case errors.Is(err, meta.ErrNotInitialized):
+		return "LadderMoon is not initialized. Run 'lm init' first."
+	case errors.Is(err, meta.ErrDetachedHead):
+		return "HEAD is detached. LadderMoon keeps META per branch, check out a branch first."
+	case errors.Is(err, meta.ErrCorrupt):
+		return fmt.Sprintf("META data is corrupt: %s. Run 'lm meta log' to find the commit that broke it.", err)
+	case errors.As(err, &gitErr):
+		return gitErr.Error()
+	}
+	return err.Error()
+}
+
+// reportError prints the error of a command unless the command already did
+func reportError(err error) {
+	if errorReported {
+		return
+	}
+	printError(describeError(err))
+	if !commandRan {
+		fmt.Fprintln(os.Stderr, "Run 'lm --help' for usage.")
+	}
+}
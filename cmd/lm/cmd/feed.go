@@ -1,11 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/laddermoon/laddermoon/pkg/items"
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
 )
@@ -30,22 +30,29 @@ func init() {
 }
 
 func runFeed(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check if we're in a git repository
-	_, err := meta.GetGitRoot()
+	_, err := meta.GetGitRoot(ctx)
 	if err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
 	// Check if initialized
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized.")
 		printInfo("Run 'lm init' to initialize.")
 		return meta.ErrNotInitialized
 	}
 
 	// Check if skills are installed
-	if !meta.SkillsInstalled() {
+	if !meta.SkillsInstalled(ctx) {
 		printError("LadderMoon skills are not installed.")
 		printInfo("Run 'lm init' to reinstall.")
 		return fmt.Errorf("skills not installed")
@@ -58,48 +65,61 @@ func runFeed(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("empty feed content")
 	}
 
-	// Acquire lock for serialized META operations
-	printInfo("Acquiring META lock...")
-	lock, err := meta.AcquireMetaLock()
+	feedID, questionIDs, err := recordFeed(ctx, store, content)
 	if err != nil {
-		printError("Failed to acquire lock: " + err.Error())
 		return err
 	}
+
+	printInfo("Processing with AI...")
+
+	// Invoke Claude Code with the laddermoon-feed skill, passing feed ID.
+	// The META lock is not held meanwhile: the skill commits through lm.
+	err = invokeFeedSkill(ctx, feedID, content, questionIDs)
+	settleItems(ctx, store, items.TypeQuestion, questionIDs)
+	if err != nil {
+		printError("Failed to process feed: " + err.Error())
+		if !interrupted(err) {
+			printInfo("Make sure 'claude' CLI is installed and configured.")
+		}
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("Feed #%d recorded and processed!", feedID))
+	return nil
+}
+
+// recordFeed records a feed to UserFeed.log and reserves the IDs of the
+// questions its skill may file, under the META lock
+func recordFeed(ctx context.Context, store meta.MetaStore, content string) (int, []string, error) {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
 	defer lock.Release()
 
 	// Get next feed ID
-	feedID, err := meta.GetNextFeedID()
+	feedID, err := meta.GetNextFeedID(store)
 	if err != nil {
 		printError("Failed to get feed ID: " + err.Error())
-		return err
+		return 0, nil, err
 	}
 
 	printInfo(fmt.Sprintf("Recording Feed #%d...", feedID))
 	printInfo("Content: " + truncateString(content, 60))
 
-	// Record to UserFeed.log
-	if err := meta.RecordUserFeed(feedID, content); err != nil {
+	// Record to UserFeed.log and increment feed ID for next use in one commit
+	if err := meta.RecordFeed(store, feedID, content); err != nil {
 		printError("Failed to record feed: " + err.Error())
-		return err
+		return 0, nil, err
 	}
 
-	// Increment feed ID for next use
-	if err := meta.IncrementFeedID(feedID); err != nil {
-		printError("Failed to increment feed ID: " + err.Error())
-		return err
-	}
-
-	printInfo("Processing with AI...")
-
-	// Invoke Claude Code with the laddermoon-feed skill, passing feed ID
-	if err := invokeFeedSkill(feedID, content); err != nil {
-		printError("Failed to process feed: " + err.Error())
-		printInfo("Make sure 'claude' CLI is installed and configured.")
-		return err
+	// Questions the skill files for conflicts get IDs from lm, not the AI
+	questionIDs, err := meta.ReserveItemIDs(store, items.TypeQuestion, reservedIDs)
+	if err != nil {
+		printError("Failed to reserve question IDs: " + err.Error())
+		return 0, nil, err
 	}
-
-	printSuccess(fmt.Sprintf("Feed #%d recorded and processed!", feedID))
-	return nil
+	return feedID, questionIDs, nil
 }
 
 func truncateString(s string, maxLen int) string {
@@ -109,15 +129,12 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// invokeFeedSkill invokes the laddermoon-feed skill with feed ID and content
-func invokeFeedSkill(feedID int, content string) error {
-	prompt := fmt.Sprintf("Use the laddermoon-feed skill to process Feed #%d:\n\n%s", feedID, content)
+// invokeFeedSkill invokes the laddermoon-feed skill with feed ID, content
+// and the IDs to file questions under
+func invokeFeedSkill(ctx context.Context, feedID int, content string, questionIDs []string) error {
+	prompt := fmt.Sprintf("Use the laddermoon-feed skill to process Feed #%d:\n\n%s\n\n%s",
+		feedID, content, describeItemIDs(items.TypeQuestion, questionIDs))
 
 	// Use interactive mode (not -p) because the skill needs to modify files
-	cmd := exec.Command("claude", prompt)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return runAgent(ctx, true, prompt)
 }
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcArchive    bool
+	gcDelete     bool
+	gcRetainDays int
+	gcForce      bool
+	gcDryRun     bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Prune and compact the laddermoon-meta branch",
+	Long: `Clean up the laddermoon-meta shadow branch:
+
+1. Branch directories whose git branch was deleted are listed, and you
+   choose for each whether to archive it (move it under .archive/),
+   delete it or keep it. --archive or --delete handle all of them at once.
+2. With --retain-days N, history older than N days is squashed into one
+   snapshot commit. Tagged META commits are kept as snapshots of their
+   own and their tags are moved along. History already pushed to a remote
+   is only squashed with --force; teammates must then re-clone the branch.
+3. Leftover .lm-tmp-* worktrees of interrupted skills older than an hour
+   are removed.
+
+Example:
+  lm gc                       # Review stale directories, clean worktrees
+  lm gc --archive             # Archive all stale directories
+  lm gc --retain-days 90      # Also squash history older than 90 days
+  lm gc --dry-run             # Only show what would be done`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.NoArgs(cmd, args); err != nil {
+			return err
+		}
+		if gcArchive && gcDelete {
+			return fmt.Errorf("--archive and --delete can't be used together")
+		}
+		return nil
+	},
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcArchive, "archive", false, "Archive all directories of deleted branches")
+	gcCmd.Flags().BoolVar(&gcDelete, "delete", false, "Delete all directories of deleted branches")
+	gcCmd.Flags().IntVar(&gcRetainDays, "retain-days", 0, "Squash META history older than this many days (0: keep all)")
+	gcCmd.Flags().BoolVar(&gcForce, "force", false, "Squash history even if it was shared with a remote")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Show what would be done without changing anything")
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := requireShadowStore(ctx); err != nil {
+		return err
+	}
+
+	if !meta.IsInitialized(ctx) {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if err := gcStaleDirs(ctx); err != nil {
+		return err
+	}
+	if gcRetainDays > 0 {
+		if err := gcSquash(ctx); err != nil {
+			return err
+		}
+	}
+	if err := gcTempWorktrees(ctx); err != nil {
+		return err
+	}
+
+	if gcDryRun {
+		printSuccess("Dry run finished, nothing was changed.")
+	} else {
+		printSuccess("META garbage collection finished!")
+	}
+	return nil
+}
+
+// gcStaleDirs archives or deletes the directories of deleted branches
+func gcStaleDirs(ctx context.Context) error {
+	stale, err := meta.StaleBranchDirs(ctx)
+	if err != nil {
+		printError("Failed to find stale META directories: " + err.Error())
+		return err
+	}
+	if len(stale) == 0 {
+		printInfo("No META directories of deleted branches.")
+		return nil
+	}
+
+	var archive, remove []string
+	for _, d := range stale {
+		desc := fmt.Sprintf("%s (branch %s, last changed %s)", d.Dir, d.Branch, d.LastChange.Format("2006-01-02"))
+		switch {
+		case gcArchive:
+			archive = append(archive, d.Dir)
+			printInfo("Archive " + desc)
+		case gcDelete:
+			remove = append(remove, d.Dir)
+			printInfo("Delete " + desc)
+		case gcDryRun:
+			printInfo("Stale " + desc)
+		default:
+			choice, err := askStaleDir(ctx, desc)
+			if err != nil {
+				return err
+			}
+			switch choice {
+			case "a":
+				archive = append(archive, d.Dir)
+			case "d":
+				remove = append(remove, d.Dir)
+			}
+		}
+	}
+
+	if gcDryRun {
+		return nil
+	}
+	if err := meta.ArchiveBranchDirs(ctx, archive); err != nil {
+		printError(err.Error())
+		return err
+	}
+	if err := meta.DeleteBranchDirs(ctx, remove); err != nil {
+		printError(err.Error())
+		return err
+	}
+	if len(archive) > 0 {
+		printInfo(fmt.Sprintf("Archived %d directories under %s/", len(archive), meta.ArchiveDir))
+	}
+	if len(remove) > 0 {
+		printInfo(fmt.Sprintf("Deleted %d directories", len(remove)))
+	}
+	return nil
+}
+
+// askStaleDir asks what to do with a stale directory: "a", "d" or "k"
+func askStaleDir(ctx context.Context, desc string) (string, error) {
+	for {
+		fmt.Printf("\nDeleted branch: %s\n", desc)
+		fmt.Print("[a] Archive  [d] Delete  [k] Keep: ")
+
+		choice, err := readLine(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		switch strings.ToLower(choice) {
+		case "a", "archive":
+			return "a", nil
+		case "d", "delete":
+			return "d", nil
+		case "k", "keep", "":
+			return "k", nil
+		}
+	}
+}
+
+// gcSquash squashes history beyond the retention window
+func gcSquash(ctx context.Context) error {
+	cutoff := time.Now().AddDate(0, 0, -gcRetainDays)
+	if gcDryRun {
+		printInfo(fmt.Sprintf("Would squash META history before %s", cutoff.Format("2006-01-02")))
+		return nil
+	}
+
+	squash, err := meta.SquashHistory(ctx, cutoff, gcForce)
+	if err != nil {
+		if errors.Is(err, meta.ErrSharedHistory) {
+			printError(err.Error() + ". Squashing rewrites it; use --force if every teammate can re-clone it.")
+			return err
+		}
+		printError("Failed to squash META history: " + err.Error())
+		return err
+	}
+
+	if squash.Snapshot == "" {
+		printInfo(fmt.Sprintf("No META history before %s to squash.", cutoff.Format("2006-01-02")))
+		return nil
+	}
+	printInfo(fmt.Sprintf("Squashed %d commits into snapshot %s, rewrote %d newer commits",
+		squash.Squashed, shortCommit(squash.Snapshot), squash.Kept))
+	for _, tag := range sortedKeys(squash.Retagged) {
+		printInfo(fmt.Sprintf("Moved tag %s to %s", tag, shortCommit(squash.Retagged[tag])))
+	}
+	return nil
+}
+
+// gcTempWorktrees removes leftover worktrees of interrupted skills
+func gcTempWorktrees(ctx context.Context) error {
+	worktrees, err := meta.TempWorktrees(ctx, meta.TempWorktreeMinAge)
+	if err != nil {
+		printError("Failed to list temporary worktrees: " + err.Error())
+		return err
+	}
+	if len(worktrees) == 0 {
+		printInfo("No leftover temporary worktrees.")
+		return nil
+	}
+
+	for _, wt := range worktrees {
+		if gcDryRun {
+			printInfo("Would remove " + wt.Path)
+			continue
+		}
+		if err := meta.RemoveTempWorktree(ctx, wt); err != nil {
+			printError(fmt.Sprintf("Failed to remove %s: %s", wt.Path, err))
+			return err
+		}
+		printInfo("Removed " + wt.Path)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var metaShowAt string
+
+var metaLogCmd = &cobra.Command{
+	Use:   "log [file]",
+	Short: "Show the history of META or one META file",
+	Long: `List the changes of the current branch's META, newest first.
+With a file, only the changes of that file are listed.
+
+Example:
+  lm meta log
+  lm meta log META.md
+  lm meta log Issues/issue-001-wrong-api.md`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMetaLog,
+}
+
+var metaShowCmd = &cobra.Command{
+	Use:   "show [file]",
+	Short: "Show a META file as it was at a point in time",
+	Long: `Print a META file (META.md by default) as it was at a point in time.
+
+--at accepts:
+- a commit of the laddermoon-meta branch
+- a code commit: the META state that was synced to it by 'lm sync', or
+  to its closest synced ancestor
+- a date: YYYY-MM-DD, 'YYYY-MM-DD HH:MM[:SS]' or RFC 3339
+
+Example:
+  lm meta show --at v1.2.0             # What META said at release time
+  lm meta show --at 2024-05-01
+  lm meta show --at 3f2a9c1 UserFeed.log`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMetaShow,
+}
+
+var metaDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b> [file]",
+	Short: "Show how META changed between two points in time",
+	Long: `Show the changes of the current branch's META between two points in time.
+Both points accept the same forms as 'lm meta show --at'.
+
+Example:
+  lm meta diff v1.1.0 v1.2.0
+  lm meta diff 2024-05-01 2024-06-01 META.md`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runMetaDiff,
+}
+
+func init() {
+	metaShowCmd.Flags().StringVar(&metaShowAt, "at", "", "META commit, code commit or date (default: latest)")
+	metaCmd.AddCommand(metaLogCmd)
+	metaCmd.AddCommand(metaShowCmd)
+	metaCmd.AddCommand(metaDiffCmd)
+}
+
+func runMetaLog(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	filename := ""
+	if len(args) > 0 {
+		filename = args[0]
+	}
+
+	history, err := store.History(filename)
+	if err != nil {
+		printError("Failed to read META history: " + err.Error())
+		return err
+	}
+
+	if len(history) == 0 {
+		printInfo("No META history found.")
+		return nil
+	}
+
+	for _, rev := range history {
+		operation := "-"
+		if rev.Trailers != nil && rev.Trailers.Operation != "" {
+			operation = rev.Trailers.Operation
+		}
+		fmt.Printf("%s  %s  %-8s %s\n", shortCommit(rev.ID), rev.Time.Format("2006-01-02 15:04"), operation, rev.Message)
+	}
+	return nil
+}
+
+func runMetaShow(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	store, err := requireShadowStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	filename := meta.MetaFileName
+	if len(args) > 0 {
+		filename = args[0]
+	}
+
+	at := metaShowAt
+	if at == "" {
+		at = meta.BranchName
+	}
+	point, err := resolveMetaPoint(store, at)
+	if err != nil {
+		return err
+	}
+
+	content, ok, err := store.ReadAt(point.Commit, filename)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read %s at META %s: %s", filename, shortCommit(point.Commit), err))
+		return err
+	}
+	if !ok {
+		printError(fmt.Sprintf("%s did not exist at META %s.", filename, shortCommit(point.Commit)))
+		return fmt.Errorf("%s: %w", filename, meta.ErrNotFound)
+	}
+
+	if content == "" {
+		printInfo(filename + " is empty.")
+		return nil
+	}
+
+	fmt.Println(content)
+	return nil
+}
+
+func runMetaDiff(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	store, err := requireShadowStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	from, err := resolveMetaPoint(store, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := resolveMetaPoint(store, args[1])
+	if err != nil {
+		return err
+	}
+
+	filename := ""
+	if len(args) > 2 {
+		filename = args[2]
+	}
+
+	diff, err := store.Diff(from.Commit, to.Commit, filename)
+	if err != nil {
+		printError("Failed to diff META: " + err.Error())
+		return err
+	}
+
+	if diff == "" {
+		printInfo("No META changes.")
+		return nil
+	}
+
+	fmt.Print(diff)
+	return nil
+}
+
+// resolveMetaPoint resolves a point in time and tells the user which
+// META state it stands for
+func resolveMetaPoint(store *meta.ShadowStore, at string) (*meta.MetaPoint, error) {
+	point, err := store.ResolveAt(at)
+	if err != nil {
+		printError(err.Error())
+		return nil, err
+	}
+
+	switch {
+	case point.Kind == meta.AtCodeCommit && !point.Exact:
+		printInfo(fmt.Sprintf("%s: META was not synced to it, using META %s synced to its ancestor %s",
+			at, shortCommit(point.Commit), shortCommit(point.SyncedTo)))
+	case point.Kind == meta.AtCodeCommit:
+		printInfo(fmt.Sprintf("%s: using META %s synced to it", at, shortCommit(point.Commit)))
+	case point.Kind == meta.AtDate:
+		printInfo(fmt.Sprintf("%s: using META %s", at, shortCommit(point.Commit)))
+	}
+	return point, nil
+}
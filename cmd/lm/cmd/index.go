@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage the item indexes",
+	Long: `Every item type has a JSONL index next to its items, such as
+Issues/issue-meta.jsonl. lm updates it in the same commit as each item
+change; lm issues, lm tasks and lm proposals read only the index.`,
+}
+
+var indexRebuildCmd = &cobra.Command{
+	Use:   "rebuild [type]",
+	Short: "Regenerate the item indexes from the item files",
+	Long: `Regenerate the index of every item type, or of one type, from the item
+files. Only indexes that disagree with the files are rewritten, as after
+items were edited by hand or META was merged.
+
+Example:
+  lm index rebuild
+  lm index rebuild issues`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		for _, arg := range args {
+			if _, err := items.ParseType(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	RunE: runIndexRebuild,
+}
+
+func init() {
+	indexCmd.AddCommand(indexRebuildCmd)
+	rootCmd.AddCommand(indexCmd)
+}
+
+func runIndexRebuild(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	var types []items.Type
+	for _, arg := range args {
+		typ, _ := items.ParseType(arg)
+		types = append(types, typ)
+	}
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	rebuilt, err := meta.RebuildIndexes(store, types...)
+	if err != nil {
+		printError("Failed to rebuild the indexes: " + err.Error())
+		return err
+	}
+	if len(rebuilt) == 0 {
+		printSuccess("Indexes already agree with the item files.")
+		return nil
+	}
+	for _, typ := range rebuilt {
+		printInfo(fmt.Sprintf("Rebuilt %s", meta.IndexFile(typ)))
+	}
+	printSuccess(fmt.Sprintf("%d index(es) rebuilt.", len(rebuilt)))
+	return nil
+}
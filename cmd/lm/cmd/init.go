@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/laddermoon/laddermoon/skills"
 	"github.com/spf13/cobra"
 )
 
-var reinstallSkills bool
+var (
+	reinstallSkills bool
+	initFrom        string
+	initEmpty       bool
+)
 
 var initCmd = &cobra.Command{
 	Use:   "init",
@@ -17,33 +24,54 @@ and setting up the META structure.
 This command must be run in a Git-managed repository and can only be
 executed once per repository.
 
-Use --reinstall-skills to reinstall skills without re-initializing META.`,
+On a feature branch, META is forked from the parent branch: META.md,
+the sync state, the feed and decision logs and open items are copied,
+with the closed items they link to, and the fork point is recorded. The
+item indexes are rebuilt for the copied items. The parent is detected as the branch with META that
+the current branch diverged from most recently; use --from to choose it
+or --empty to start with an empty META.
+
+Use --reinstall-skills to reinstall skills without re-initializing META.
+
+Example:
+  lm init
+  lm init --from main
+  lm init --empty`,
 	RunE: runInit,
 }
 
 func init() {
 	initCmd.Flags().BoolVar(&reinstallSkills, "reinstall-skills", false, "Reinstall skills only (use if already initialized)")
+	initCmd.Flags().StringVar(&initFrom, "from", "", "Fork META from this branch (default: detected parent branch)")
+	initCmd.Flags().BoolVar(&initEmpty, "empty", false, "Start with an empty META instead of forking the parent branch")
 	rootCmd.AddCommand(initCmd)
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check if we're in a git repository
-	gitRoot, err := meta.GetGitRoot()
+	gitRoot, err := meta.GetGitRoot(ctx)
 	if err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Handle --reinstall-skills flag
 	if reinstallSkills {
-		if !meta.IsInitialized() {
+		if !store.Initialized() {
 			printError("LadderMoon is not initialized. Run 'lm init' first.")
 			return meta.ErrNotInitialized
 		}
 
 		printInfo("Reinstalling LadderMoon skills...")
 
-		if err := meta.InstallSkills(skills.SkillsFS, skills.SkillNames); err != nil {
+		if err := meta.InstallSkills(ctx, skills.SkillsFS, skills.SkillNames); err != nil {
 			printError("Failed to install skills: " + err.Error())
 			return err
 		}
@@ -56,14 +84,16 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Check if already initialized for this branch
-	branchInitialized, err := meta.BranchMetaDirExists()
+	// Forking reads the parent's META and registers the branch; the lock
+	// keeps other commands from changing either meanwhile
+	lock, err := acquireMetaLock(ctx)
 	if err != nil {
-		printError("Failed to check initialization status: " + err.Error())
 		return err
 	}
+	defer lock.Release()
 
-	if branchInitialized {
+	// Check if already initialized for this branch
+	if store.Initialized() {
 		printError("LadderMoon is already initialized for this branch.")
 		printInfo("Use --reinstall-skills to reinstall skills.")
 		return meta.ErrAlreadyInit
@@ -72,31 +102,42 @@ func runInit(cmd *cobra.Command, args []string) error {
 	printInfo("Initializing LadderMoon...")
 	printInfo("Git root: " + gitRoot)
 
-	// Create META structure
-	if err := meta.InitMetaStructure(); err != nil {
-		printError("Failed to initialize: " + err.Error())
+	// Fork META from the parent branch, or create an empty META structure
+	fork, err := forkParentMeta(ctx, store)
+	if err != nil {
+		printError("Failed to fork META: " + err.Error())
 		return err
 	}
+	if fork == nil {
+		if err := store.Init(); err != nil {
+			printError("Failed to initialize: " + err.Error())
+			return err
+		}
+	}
 
 	printInfo("Installing LadderMoon skills...")
 
 	// Install skills to .claude/skills
-	if err := meta.InstallSkills(skills.SkillsFS, skills.SkillNames); err != nil {
+	if err := meta.InstallSkills(ctx, skills.SkillsFS, skills.SkillNames); err != nil {
 		printError("Failed to install skills: " + err.Error())
 		return err
 	}
 
 	// Get current branch for display
-	currentBranch, _ := meta.GetCurrentBranch()
+	currentBranch, _ := meta.GetCurrentBranch(ctx)
 
 	printSuccess("LadderMoon initialized successfully!")
 	printInfo("Branch: " + currentBranch)
-	printInfo("Shadow branch: laddermoon-meta")
-	printInfo("META structure:")
-	printInfo("  - META.md (empty)")
-	printInfo("  - Questions/")
-	printInfo("  - Issues/")
-	printInfo("  - Suggestions/")
+	printInfo("META store: " + store.Location())
+	if fork != nil {
+		printInfo("Forked from: " + fork.Parent + " (META " + shortCommit(fork.MetaCommit) + ")")
+	} else {
+		printInfo("META structure:")
+		printInfo("  - META.md (empty)")
+		printInfo("  - Questions/")
+		printInfo("  - Issues/")
+		printInfo("  - Proposals/")
+	}
 	printInfo("")
 	printInfo("Installed skills:")
 	for _, name := range skills.SkillNames {
@@ -110,3 +151,36 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// forkParentMeta forks the parent branch's META into the store when it is
+// a shadow branch store. It returns nil when META should start empty.
+func forkParentMeta(ctx context.Context, store meta.MetaStore) (*meta.ForkPoint, error) {
+	shadow, ok := store.(*meta.ShadowStore)
+	if !ok {
+		if initFrom != "" {
+			return nil, errors.New("--from only works with the shadow branch META store")
+		}
+		return nil, nil
+	}
+	if initEmpty {
+		return nil, nil
+	}
+
+	parent := initFrom
+	if parent == "" {
+		if !meta.IsInitialized(ctx) {
+			return nil, nil
+		}
+		detected, err := meta.DetectParentBranch(ctx)
+		if errors.Is(err, meta.ErrNoParentBranch) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent = detected
+	}
+
+	printInfo("Forking META from branch: " + parent)
+	return shadow.Fork(parent)
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var (
+	itemStatusReason string
+	itemLabelRemove  bool
+)
+
+var itemCmd = &cobra.Command{
+	Use:   "item",
+	Short: "Manage Issues, Questions, Proposals and Tasks",
+}
+
+var itemStatusCmd = &cobra.Command{
+	Use:   "status <id> <status>",
+	Short: "Move an item to another status",
+	Long: `Move an item to another status of its lifecycle. Changes the lifecycle
+doesn't allow are refused. Every change is recorded in the item's history
+with who made it, when and why.
+
+Lifecycles:
+` + describeLifecycles() + `
+Example:
+  lm item status issue-001 confirmed --reason "Reproduced on main"
+  lm item status task-003 in-progress`,
+	Args: cobra.ExactArgs(2),
+	RunE: runItemStatus,
+}
+
+var itemPriorityCmd = &cobra.Command{
+	Use:   "priority <id> <P0|P1|P2|P3|none>",
+	Short: "Set the priority of an item",
+	Long: `Set the priority of an item, from P0 (most urgent) to P3, or clear it
+with none. Tasks created from an item take over its priority.
+
+Example:
+  lm item priority issue-001 P1
+  lm item priority task-003 none`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+			return err
+		}
+		if args[1] == "none" {
+			return nil
+		}
+		_, err := items.ParsePriority(args[1])
+		return err
+	},
+	RunE: runItemPriority,
+}
+
+var itemLabelCmd = &cobra.Command{
+	Use:   "label <id> <label>...",
+	Short: "Add or remove labels of an item",
+	Long: `Add free-form labels to an item, or remove them with --remove.
+'lm issues --label' and the other list commands filter by label.
+
+Example:
+  lm item label issue-001 api security
+  lm item label issue-001 security --remove`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runItemLabel,
+}
+
+var itemLinkCmd = &cobra.Command{
+	Use:   "link <task> <blocks|blocked-by> <task>",
+	Short: "Make a task depend on another",
+	Long: `Record that a task must be done before another. The dependency is
+linked on both tasks. Dependencies that would make a task wait for itself
+are refused.
+
+Example:
+  lm item link task-002 blocked-by task-001   # task-001 first
+  lm item link task-001 blocks task-002       # the same`,
+	Args: dependencyArgs,
+	RunE: runItemLink,
+}
+
+var itemUnlinkCmd = &cobra.Command{
+	Use:   "unlink <task> <blocks|blocked-by> <task>",
+	Short: "Remove a dependency between tasks",
+	Long: `Remove a dependency recorded with 'lm item link'.
+
+Example:
+  lm item unlink task-002 blocked-by task-001`,
+	Args: dependencyArgs,
+	RunE: runItemUnlink,
+}
+
+func init() {
+	itemStatusCmd.Flags().StringVar(&itemStatusReason, "reason", "", "Why the status changes")
+	itemLabelCmd.Flags().BoolVar(&itemLabelRemove, "remove", false, "Remove the labels instead of adding them")
+	itemCmd.AddCommand(itemStatusCmd)
+	itemCmd.AddCommand(itemPriorityCmd)
+	itemCmd.AddCommand(itemLabelCmd)
+	itemCmd.AddCommand(itemLinkCmd)
+	itemCmd.AddCommand(itemUnlinkCmd)
+	rootCmd.AddCommand(itemCmd)
+}
+
+// describeLifecycles lists the statuses of every item type for the help text
+func describeLifecycles() string {
+	var b strings.Builder
+	for _, typ := range items.Types {
+		fmt.Fprintf(&b, "  %-9s %s\n", typ, strings.Join(items.Statuses(typ), ", "))
+	}
+	return b.String()
+}
+
+func runItemStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	itemID, status := args[0], args[1]
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	item, err := items.Find(store, itemID)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read %s: %s", itemID, err))
+		return err
+	}
+	if item == nil {
+		printError(fmt.Sprintf("Item not found: %s", itemID))
+		return meta.ErrNotFound
+	}
+
+	from := item.Status
+	if err := meta.SetItemStatus(store, item, status, meta.GetGitUser(ctx), itemStatusReason); err != nil {
+		printError(err.Error())
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("%s moved from %s to %s.", item.ID, from, status))
+	return nil
+}
+
+func runItemPriority(cmd *cobra.Command, args []string) error {
+	priority := ""
+	if args[1] != "none" {
+		priority, _ = items.ParsePriority(args[1])
+	}
+
+	return editItem(cmd.Context(), args[0], func(item *items.Item) string {
+		item.Priority = priority
+		if priority == "" {
+			return "no priority"
+		}
+		return "priority " + priority
+	})
+}
+
+func runItemLabel(cmd *cobra.Command, args []string) error {
+	labels := args[1:]
+
+	return editItem(cmd.Context(), args[0], func(item *items.Item) string {
+		for _, label := range labels {
+			if itemLabelRemove {
+				item.RemoveLabel(label)
+			} else {
+				item.AddLabel(label)
+			}
+		}
+		if len(item.Labels) == 0 {
+			return "no labels"
+		}
+		return "labels " + strings.Join(item.Labels, ", ")
+	})
+}
+
+// editItem applies edit to the item ref names and saves it under the META
+// lock. edit returns what the item now has, for the commit and the user.
+func editItem(ctx context.Context, ref string, edit func(*items.Item) string) error {
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	item, err := items.Find(store, ref)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read %s: %s", ref, err))
+		return err
+	}
+	if item == nil {
+		printError(fmt.Sprintf("Item not found: %s", ref))
+		return meta.ErrNotFound
+	}
+
+	change := edit(item)
+	if err := meta.EditItem(store, item, change); err != nil {
+		printError(fmt.Sprintf("Failed to update %s: %s", item.ID, err))
+		return err
+	}
+
+	printSuccess(fmt.Sprintf("%s now has %s.", item.ID, change))
+	return nil
+}
+
+// dependencyArgs validates the arguments of link and unlink
+func dependencyArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(3)(cmd, args); err != nil {
+		return err
+	}
+	if rel := args[1]; rel != items.LinkBlocks && rel != items.LinkBlockedBy {
+		return fmt.Errorf("unknown relation %q (relations: %s, %s)", rel, items.LinkBlocks, items.LinkBlockedBy)
+	}
+	return nil
+}
+
+func runItemLink(cmd *cobra.Command, args []string) error {
+	return changeDependency(cmd.Context(), args, meta.AddDependency, "%s now blocks %s.")
+}
+
+func runItemUnlink(cmd *cobra.Command, args []string) error {
+	return changeDependency(cmd.Context(), args, meta.RemoveDependency, "%s no longer blocks %s.")
+}
+
+// changeDependency applies change to the tasks named by the arguments of
+// link or unlink, under the META lock
+func changeDependency(ctx context.Context, args []string, change func(s meta.MetaStore, blocked, blocker *items.Item) error, done string) error {
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	var tasks [2]*items.Item
+	for n, ref := range []string{args[0], args[2]} {
+		task, err := items.Find(store, ref)
+		if err != nil {
+			printError(fmt.Sprintf("Failed to read %s: %s", ref, err))
+			return err
+		}
+		if task == nil {
+			printError(fmt.Sprintf("Item not found: %s", ref))
+			return meta.ErrNotFound
+		}
+		tasks[n] = task
+	}
+
+	blocked, blocker := tasks[0], tasks[1]
+	if args[1] == items.LinkBlocks {
+		blocked, blocker = blocker, blocked
+	}
+	if err := change(store, blocked, blocker); err != nil {
+		printError(err.Error())
+		return err
+	}
+
+	printSuccess(fmt.Sprintf(done, blocker.ID, blocked.ID))
+	return nil
+}
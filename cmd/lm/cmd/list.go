@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/laddermoon/laddermoon/pkg/items"
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
 )
@@ -14,8 +17,12 @@ var tasksCmd = &cobra.Command{
 	Long: `List all tasks or show a specific task.
 
 Example:
-  lm tasks                    # List all tasks
-  lm tasks task-from-issue-001  # Show specific task`,
+  lm tasks                        # List all tasks
+  lm tasks --status open,in-progress --sort priority
+  lm tasks --ready                # Tasks that can be started now
+  lm tasks --graph                # Dependency tree
+  lm tasks task-001               # Show specific task`,
+	Args: itemListArgs(items.TypeTask),
 	RunE: runTasks,
 }
 
@@ -25,8 +32,11 @@ var issuesCmd = &cobra.Command{
 	Long: `List all issues or show a specific issue.
 
 Example:
-  lm issues              # List all issues
-  lm issues issue-001    # Show specific issue`,
+  lm issues                       # List all issues
+  lm issues --priority P0,P1 --label api
+  lm issues --since 7d --sort updated --limit 10
+  lm issues issue-001             # Show specific issue`,
+	Args: itemListArgs(items.TypeIssue),
 	RunE: runIssues,
 }
 
@@ -36,8 +46,10 @@ var proposalsCmd = &cobra.Command{
 	Long: `List all proposals or show a specific proposal.
 
 Example:
-  lm proposals                # List all proposals
-  lm proposals proposal-001   # Show specific proposal`,
+  lm proposals                    # List all proposals
+  lm proposals --status closed
+  lm proposals proposal-001       # Show specific proposal`,
+	Args: itemListArgs(items.TypeProposal),
 	RunE: runProposals,
 }
 
@@ -56,6 +68,10 @@ var userlogCmd = &cobra.Command{
 }
 
 func init() {
+	for _, cmd := range []*cobra.Command{tasksCmd, issuesCmd, proposalsCmd} {
+		addItemQueryFlags(cmd)
+	}
+	addTaskQueryFlags(tasksCmd)
 	rootCmd.AddCommand(tasksCmd)
 	rootCmd.AddCommand(issuesCmd)
 	rootCmd.AddCommand(proposalsCmd)
@@ -64,24 +80,29 @@ func init() {
 }
 
 func runTasks(cmd *cobra.Command, args []string) error {
-	return showItems("Tasks", args)
+	return showItems(cmd.Context(), items.TypeTask, args)
 }
 
 func runIssues(cmd *cobra.Command, args []string) error {
-	return showItems("Issues", args)
+	return showItems(cmd.Context(), items.TypeIssue, args)
 }
 
 func runProposals(cmd *cobra.Command, args []string) error {
-	return showItems("Proposals", args)
+	return showItems(cmd.Context(), items.TypeProposal, args)
 }
 
-func showItems(directory string, args []string) error {
-	if _, err := meta.GetGitRoot(); err != nil {
+func showItems(ctx context.Context, typ items.Type, args []string) error {
+	if _, err := meta.GetGitRoot(ctx); err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized. Run 'lm init' first.")
 		return meta.ErrNotInitialized
 	}
@@ -89,88 +110,137 @@ func showItems(directory string, args []string) error {
 	// If specific ID provided, show that item
 	if len(args) > 0 {
 		itemID := args[0]
-		// Try to find the file
-		filePath := fmt.Sprintf("%s/%s.md", directory, itemID)
-		content, err := meta.ReadFile(filePath)
-		if err != nil || content == "" {
-			// Try without .md extension in the ID
-			filePath = fmt.Sprintf("%s/%s", directory, itemID)
-			content, err = meta.ReadFile(filePath)
-			if err != nil || content == "" {
-				printError(fmt.Sprintf("Item not found: %s", itemID))
-				return fmt.Errorf("item not found")
-			}
+		itemPath, found, err := meta.FindItemPath(store, typ, itemID)
+		if err != nil {
+			printError(fmt.Sprintf("Failed to read %s: %s", itemID, err))
+			printInfo("Run 'lm index rebuild' to regenerate the index.")
+			return err
+		}
+		if !found {
+			printError(fmt.Sprintf("Item not found: %s", itemID))
+			return meta.ErrNotFound
+		}
+		content, err := store.Read(itemPath)
+		if err != nil {
+			printError(fmt.Sprintf("Failed to read %s: %s", itemID, err))
+			return err
 		}
 		fmt.Println(content)
 		return nil
 	}
 
-	// List all items
-	files, err := meta.GetMetaFileList()
+	// List all items from the index, without reading the item files
+	entries, found, err := meta.ReadIndex(store, typ)
 	if err != nil {
-		printError("Failed to get file list: " + err.Error())
+		printError("Failed to read the index: " + err.Error())
+		printInfo("Run 'lm index rebuild' to regenerate it.")
 		return err
 	}
-
-	items := []string{}
-	for _, f := range files {
-		if strings.HasPrefix(f, directory+"/") {
-			items = append(items, f)
+	if !found {
+		// META from before indexes existed: read the item files instead
+		entries, err = meta.BuildIndexEntries(store, typ)
+		if err = skipBrokenItems(err); err != nil {
+			printError(fmt.Sprintf("Failed to read %s: %s", strings.ToLower(typ.Dir()), err))
+			return err
+		}
+		if len(entries) > 0 {
+			printInfo("No index of " + strings.ToLower(typ.Dir()) + " yet. Run 'lm index rebuild' to list them faster.")
 		}
 	}
 
-	if len(items) == 0 {
+	directory := typ.Dir()
+	matching := queryIndex(typ, entries)
+	if len(matching) == 0 {
 		printInfo(fmt.Sprintf("No %s found.", strings.ToLower(directory)))
 		return nil
 	}
 
-	fmt.Printf("%s (%d):\n", directory, len(items))
-	for _, item := range items {
-		// Get status from content
-		content, _ := meta.ReadFile(item)
-		status := "Unknown"
-		if strings.Contains(content, "**Status**: Open") {
-			status = "Open"
-		} else if strings.Contains(content, "**Status**: Resolved") {
-			status = "Resolved"
-		} else if strings.Contains(content, "**Status**: Approved") {
-			status = "Approved"
-		} else if strings.Contains(content, "**Status**: Rejected") {
-			status = "Rejected"
-		}
+	if itemQuery.graph {
+		fmt.Printf("%s (%d):\n", directory, len(matching))
+		printTaskGraph(matching)
+		return nil
+	}
 
-		// Extract title from first heading
-		title := extractTitle(content)
-		name := strings.TrimPrefix(item, directory+"/")
-		fmt.Printf("  [%s] %s - %s\n", status, name, title)
+	deps := indexDeps(entries)
+	if len(matching) < len(entries) {
+		fmt.Printf("%s (%d of %d):\n", directory, len(matching), len(entries))
+	} else {
+		fmt.Printf("%s (%d):\n", directory, len(entries))
+	}
+	for _, entry := range matching {
+		status := entry.Status
+		if status == "" {
+			status = "unknown"
+		}
+		var details []string
+		if entry.Priority != "" {
+			details = append(details, entry.Priority)
+		}
+		details = append(details, entry.Labels...)
+		if waiting := waitingFor(entry, deps, entries); len(waiting) > 0 {
+			details = append(details, "blocked by "+strings.Join(waiting, ", "))
+		}
+		suffix := ""
+		if len(details) > 0 {
+			suffix = " (" + strings.Join(details, ", ") + ")"
+		}
+		fmt.Printf("  [%s] %s - %s%s\n", status, entry.ID, entry.Title, suffix)
 	}
 
 	return nil
 }
 
-func extractTitle(content string) string {
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# ") {
-			return strings.TrimPrefix(line, "# ")
+// skipBrokenItems warns about each item file of an items.FileErrors and
+// returns nil, so the items that did load are still used. Other errors
+// are returned as they are.
+func skipBrokenItems(err error) error {
+	var bad items.FileErrors
+	if !errors.As(err, &bad) {
+		return err
+	}
+	for _, e := range bad {
+		printWarning("Skipped an item that can't be parsed: " + e.Error())
+	}
+	return nil
+}
+
+// findOpenItems returns the open items of a type
+func findOpenItems(store meta.MetaStore, typ items.Type) ([]*items.Item, error) {
+	all, err := items.LoadAll(store, typ)
+	if err = skipBrokenItems(err); err != nil {
+		printError(fmt.Sprintf("Failed to read %s: %s", strings.ToLower(typ.Dir()), err))
+		return nil, err
+	}
+
+	var open []*items.Item
+	for _, item := range all {
+		if item.IsOpen() {
+			open = append(open, item)
 		}
 	}
-	return ""
+	return open, nil
 }
 
 func runMeta(cmd *cobra.Command, args []string) error {
-	if _, err := meta.GetGitRoot(); err != nil {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized. Run 'lm init' first.")
 		return meta.ErrNotInitialized
 	}
 
-	content, err := meta.ReadMetaFile()
-	if err != nil {
+	content, err := store.Read(meta.MetaFileName)
+	if err != nil && !errors.Is(err, meta.ErrNotFound) {
 		printError("Failed to read META.md: " + err.Error())
 		return err
 	}
@@ -185,18 +255,25 @@ func runMeta(cmd *cobra.Command, args []string) error {
 }
 
 func runUserlog(cmd *cobra.Command, args []string) error {
-	if _, err := meta.GetGitRoot(); err != nil {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized. Run 'lm init' first.")
 		return meta.ErrNotInitialized
 	}
 
-	content, err := meta.ReadFile("UserFeed.log")
-	if err != nil {
+	content, err := store.Read(meta.UserFeedLog)
+	if err != nil && !errors.Is(err, meta.ErrNotFound) {
 		printError("Failed to read UserFeed.log: " + err.Error())
 		return err
 	}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var metaMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate legacy META branch directories and items",
+	Long: `Assign every unregistered directory on the laddermoon-meta branch to its
+git branch and record it in the branch registry (.branches.json).
+
+Older versions mapped 'feature/x' to the directory 'feature_x', so
+'feature/a_b' and 'feature_a/b' shared one directory. Directories now use a
+reversible encoding ('feature%2Fx'). Legacy directories that match exactly
+one local branch are renamed; the others are reported and left alone.
+
+The migration runs automatically the first time a command opens an
+unmigrated META branch. Run it again after checking out the branches of
+unresolved directories.
+
+Items (Issues, Questions, Proposals and Tasks) written by older skills keep
+their fields as bold Markdown lines ('**Status**: Open'). They are rewritten
+with YAML frontmatter; fields lm doesn't know are kept, and [Feed #N] and
+[Source: path] citations become sources. lm reads both formats, so this
+part only runs when asked for. Proposals older versions filed in
+Suggestions/ are moved to Proposals/ the first time META is opened.
+
+Example:
+  lm meta migrate`,
+	Args: cobra.NoArgs,
+	RunE: runMetaMigrate,
+}
+
+func init() {
+	metaCmd.AddCommand(metaMigrateCmd)
+}
+
+func runMetaMigrate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+	if !meta.IsInitialized(ctx) {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+	if err := migrateBranchDirs(ctx); err != nil {
+		return err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+	if !store.Initialized() {
+		return nil
+	}
+	return migrateItems(ctx, store)
+}
+
+// migrateItems converts the items of the current branch to frontmatter
+// under the META lock
+func migrateItems(ctx context.Context, store meta.MetaStore) error {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	migrated, err := meta.MigrateItems(store)
+	if err != nil {
+		printError("Failed to migrate items: " + err.Error())
+		return err
+	}
+	if len(migrated) == 0 {
+		printInfo("All items already use frontmatter.")
+		return nil
+	}
+	for _, item := range migrated {
+		printInfo("Migrated item " + item.Path)
+	}
+	printSuccess(fmt.Sprintf("%d item(s) migrated to frontmatter!", len(migrated)))
+	return nil
+}
+
+// migrateLegacyProposals moves the proposals older versions filed outside
+// Proposals/ under the META lock
+func migrateLegacyProposals(ctx context.Context, store meta.MetaStore) error {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	moved, err := meta.MoveLegacyProposals(store)
+	if err != nil {
+		printError("Failed to move proposals: " + err.Error())
+		return err
+	}
+	printSuccess(fmt.Sprintf("%d file(s) moved to Proposals/!", len(moved)))
+	return nil
+}
+
+// migrateBranchDirs runs the branch directory migration under the META lock
+// and reports its outcome
+func migrateBranchDirs(ctx context.Context) error {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	migration, err := meta.MigrateBranchDirs(ctx)
+	if err != nil {
+		printError(err.Error())
+		return err
+	}
+
+	for _, from := range sortedKeys(migration.Renamed) {
+		printInfo(fmt.Sprintf("Renamed META directory %s to %s", from, migration.Renamed[from]))
+	}
+	for _, dir := range migration.Registered {
+		printInfo("Registered META directory " + dir)
+	}
+	for _, dir := range sortedKeys(migration.Unresolved) {
+		if candidates := migration.Unresolved[dir]; len(candidates) > 0 {
+			printInfo(fmt.Sprintf("Skipped META directory %s: matches several branches (%s)", dir, strings.Join(candidates, ", ")))
+		} else {
+			printInfo(fmt.Sprintf("Skipped META directory %s: no local branch matches it", dir))
+		}
+	}
+	printSuccess("META branch directories migrated!")
+	return nil
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
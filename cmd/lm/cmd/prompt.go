@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// errNoAnswer is returned by a prompt when stdin is closed
+var errNoAnswer = errors.New("no answer: stdin is closed")
+
+// stdinLine is a line read from stdin
+type stdinLine struct {
+	text string
+	err  error
+}
+
+var (
+	stdinMu sync.Mutex
+	// pendingLine receives the line being read from stdin. A prompt that
+	// gives up leaves the read running, and the next prompt waits for that
+	// line instead of starting a second read.
+	pendingLine chan stdinLine
+)
+
+// readLine reads an answer from stdin, giving up when ctx is done. Every
+// prompt reads through it, so stdin has at most one reader. It returns
+// errNoAnswer when stdin is closed before a line was read.
+func readLine(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	stdinMu.Lock()
+	if pendingLine == nil {
+		pendingLine = make(chan stdinLine, 1)
+		go func(line chan<- stdinLine) {
+			text, err := readStdinLine()
+			line <- stdinLine{text, err}
+		}(pendingLine)
+	}
+	line := pendingLine
+	stdinMu.Unlock()
+
+	select {
+	case l := <-line:
+		stdinMu.Lock()
+		pendingLine = nil
+		stdinMu.Unlock()
+		if errors.Is(l.err, io.EOF) {
+			fmt.Println()
+			return "", errNoAnswer
+		}
+		return l.text, l.err
+	case <-ctx.Done():
+		fmt.Println()
+		return "", ctx.Err()
+	}
+}
+
+// readStdinLine reads a line from stdin without buffering past it, so
+// $EDITOR and interactive agents see the rest of the input
+func readStdinLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+			continue
+		}
+		if err != nil {
+			if len(line) == 0 {
+				return "", err
+			}
+			break
+		}
+	}
+	return strings.TrimSpace(string(line)), nil
+}
@@ -1,11 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"strings"
 
+	"github.com/laddermoon/laddermoon/pkg/items"
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
 )
@@ -30,18 +29,25 @@ func init() {
 }
 
 func runPropose(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check prerequisites
-	if _, err := meta.GetGitRoot(); err != nil {
+	if _, err := meta.GetGitRoot(ctx); err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized. Run 'lm init' first.")
 		return meta.ErrNotInitialized
 	}
 
-	if !meta.SkillsInstalled() {
+	if !meta.SkillsInstalled(ctx) {
 		printError("LadderMoon skills are not installed.")
 		printInfo("Run 'lm init' to reinstall.")
 		return fmt.Errorf("skills not installed")
@@ -49,13 +55,16 @@ func runPropose(cmd *cobra.Command, args []string) error {
 
 	// Step 1: Invoke propose skill to find suggestions
 	printInfo("Step 1: Analyzing project for improvement suggestions...")
-	if err := invokeProposeSkill(); err != nil {
+	if err := invokeProposeSkill(ctx, store); err != nil {
 		printError("Failed to propose: " + err.Error())
 		return err
 	}
 
 	// Step 2: Find open proposals and let user verify
-	proposals := findOpenProposals()
+	proposals, err := findOpenItems(store, items.TypeProposal)
+	if err != nil {
+		return err
+	}
 	if len(proposals) == 0 {
 		printSuccess("No proposals found.")
 		return nil
@@ -63,42 +72,8 @@ func runPropose(cmd *cobra.Command, args []string) error {
 
 	printInfo(fmt.Sprintf("\nFound %d proposal(s). Review each to decide if it should become a Task:\n", len(proposals)))
 
-	for _, proposal := range proposals {
-		// Display proposal content
-		content, err := meta.ReadFile(proposal)
-		if err != nil || content == "" {
-			continue
-		}
-
-		fmt.Println(strings.Repeat("=", 60))
-		fmt.Printf("Proposal: %s\n", proposal)
-		fmt.Println(strings.Repeat("=", 60))
-		fmt.Println(content)
-		fmt.Println(strings.Repeat("-", 60))
-
-		fmt.Println("Options:")
-		fmt.Println("  [a] Approve - Create a Task for this proposal")
-		fmt.Println("  [r] Reject  - Not a good proposal")
-		fmt.Println("  [s] Skip    - Decide later")
-		fmt.Println("  [q] Quit    - Stop reviewing")
-		fmt.Print("\nYour choice: ")
-
-		var choice string
-		fmt.Scanln(&choice)
-
-		switch strings.ToLower(strings.TrimSpace(choice)) {
-		case "a", "approve":
-			taskFile := createTaskFromProposal(proposal)
-			printSuccess(fmt.Sprintf("Task created: %s", taskFile))
-		case "r", "reject":
-			printInfo("Proposal rejected.")
-		case "s", "skip":
-			printInfo("Skipped.")
-		case "q", "quit":
-			printInfo("Stopped reviewing.")
-			return nil
-		}
-		fmt.Println()
+	if err := triageItems(ctx, store, proposals, "proposal"); err != nil {
+		return err
 	}
 
 	printSuccess("Propose complete!")
@@ -106,50 +81,14 @@ func runPropose(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func invokeProposeSkill() error {
-	prompt := "Use the laddermoon-propose skill to propose improvements and create Proposal files."
-
-	cmd := exec.Command("claude", "-p", prompt, "--dangerously-skip-permissions")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
-}
-
-func findOpenProposals() []string {
-	files, err := meta.GetMetaFileList()
+func invokeProposeSkill(ctx context.Context, store meta.MetaStore) error {
+	ids, err := reserveItemIDs(ctx, store, items.TypeProposal)
 	if err != nil {
-		return nil
-	}
-
-	var proposals []string
-	for _, f := range files {
-		if !strings.HasPrefix(f, "Proposals/") && !strings.HasPrefix(f, "Suggestions/") {
-			continue
-		}
-		content, err := meta.ReadFile(f)
-		if err != nil || content == "" {
-			continue
-		}
-		if strings.Contains(content, "**Status**: Open") {
-			proposals = append(proposals, f)
-		}
+		return err
 	}
-	return proposals
-}
+	defer settleItems(ctx, store, items.TypeProposal, ids)
 
-func createTaskFromProposal(proposalFile string) string {
-	// Extract proposal ID from filename
-	base := proposalFile
-	if strings.HasPrefix(base, "Proposals/") {
-		base = strings.TrimPrefix(base, "Proposals/")
-	} else if strings.HasPrefix(base, "Suggestions/") {
-		base = strings.TrimPrefix(base, "Suggestions/")
-	}
-	taskID := strings.TrimSuffix(base, ".md")
-	taskFile := fmt.Sprintf("Tasks/task-from-%s.md", taskID)
+	prompt := "Use the laddermoon-propose skill to propose improvements and create Proposal files.\n\n" + describeItemIDs(items.TypeProposal, ids)
 
-	printInfo("Note: Run 'lm workon " + taskFile + "' to start working on this task.")
-	return taskFile
+	return runAgent(ctx, false, "-p", prompt, "--dangerously-skip-permissions")
 }
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+// statusClosed selects the items in a final state of their lifecycle
+const statusClosed = "closed"
+
+// Orders of the list commands
+const (
+	sortID       = "id"
+	sortPriority = "priority"
+	sortUpdated  = "updated"
+	sortStatus   = "status"
+)
+
+// itemQuery holds the filter and sort flags of the list commands
+var itemQuery struct {
+	statuses   []string
+	labels     []string
+	priorities []string
+	since      string
+	sort       string
+	limit      int
+	// ready and graph apply to tasks only
+	ready bool
+	graph bool
+
+	// sinceTime is since parsed by checkItemQuery
+	sinceTime time.Time
+}
+
+// addItemQueryFlags registers the filter and sort flags on a list command
+func addItemQueryFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVar(&itemQuery.statuses, "status", nil, "Only items in these statuses; 'closed' selects final states")
+	flags.StringSliceVar(&itemQuery.labels, "label", nil, "Only items carrying all of these labels")
+	flags.StringSliceVar(&itemQuery.priorities, "priority", nil, "Only items with these priorities (P0-P3, 'none' for unset)")
+	flags.StringVar(&itemQuery.since, "since", "", "Only items updated since a date (2006-01-02) or for a duration (36h, 7d, 2w)")
+	flags.StringVar(&itemQuery.sort, "sort", sortID, "Order by id, priority, updated (newest first) or status")
+	flags.IntVar(&itemQuery.limit, "limit", 0, "Show at most this many items (0: all)")
+}
+
+// addTaskQueryFlags registers the dependency flags of lm tasks
+func addTaskQueryFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVar(&itemQuery.ready, "ready", false, "Only tasks that can be started: open and not waiting for other tasks")
+	flags.BoolVar(&itemQuery.graph, "graph", false, "Print the dependency tree of the tasks")
+}
+
+// itemListArgs validates the arguments and query flags of the list
+// command of an item type, so mistakes exit as usage errors
+func itemListArgs(typ items.Type) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		return checkItemQuery(typ)
+	}
+}
+
+// checkItemQuery validates the query flags and normalizes their values
+func checkItemQuery(typ items.Type) error {
+	for _, status := range itemQuery.statuses {
+		if status != statusClosed && !items.ValidStatus(typ, status) {
+			return fmt.Errorf("%s is not a status of %ss (statuses: %s, %s)",
+				status, typ, strings.Join(items.Statuses(typ), ", "), statusClosed)
+		}
+	}
+	for n, priority := range itemQuery.priorities {
+		if priority == "none" {
+			continue
+		}
+		p, err := items.ParsePriority(priority)
+		if err != nil {
+			return err
+		}
+		itemQuery.priorities[n] = p
+	}
+	if itemQuery.since != "" {
+		since, err := parseSince(itemQuery.since, time.Now())
+		if err != nil {
+			return err
+		}
+		itemQuery.sinceTime = since
+	}
+	switch itemQuery.sort {
+	case sortID, sortPriority, sortUpdated, sortStatus:
+	default:
+		return fmt.Errorf("unknown order %q (orders: %s, %s, %s, %s)", itemQuery.sort, sortID, sortPriority, sortUpdated, sortStatus)
+	}
+	if itemQuery.limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	return nil
+}
+
+// parseSince parses a date, or a duration back from now in hours, days
+// (d) or weeks (w)
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimRight(s, "dw")); err == nil && n >= 0 && len(s) > 1 {
+		switch s[len(s)-1] {
+		case 'd':
+			return now.AddDate(0, 0, -n), nil
+		case 'w':
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a date (2006-01-02) or a duration (36h, 7d, 2w)", s)
+}
+
+// queryIndex filters, orders and limits the index entries of an item type
+// by the query flags
+func queryIndex(typ items.Type, entries []meta.IndexEntry) []meta.IndexEntry {
+	deps := indexDeps(entries)
+	var result []meta.IndexEntry
+	for _, e := range entries {
+		if matchesItemQuery(typ, e) && (!itemQuery.ready || isReady(e, deps, entries)) {
+			result = append(result, e)
+		}
+	}
+
+	statusRank := make(map[string]int)
+	for n, status := range items.Statuses(typ) {
+		statusRank[status] = n
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch itemQuery.sort {
+		case sortPriority:
+			if ra, rb := items.PriorityRank(a.Priority), items.PriorityRank(b.Priority); ra != rb {
+				return ra < rb
+			}
+		case sortUpdated:
+			if !a.Updated.Equal(b.Updated) {
+				return a.Updated.After(b.Updated)
+			}
+		case sortStatus:
+			if ra, rb := statusRank[a.Status], statusRank[b.Status]; ra != rb {
+				return ra < rb
+			}
+		}
+		if na, nb := items.IDNumber(a.ID), items.IDNumber(b.ID); na != nb {
+			return na < nb
+		}
+		return a.Path < b.Path
+	})
+
+	if itemQuery.limit > 0 && len(result) > itemQuery.limit {
+		result = result[:itemQuery.limit]
+	}
+	return result
+}
+
+// matchesItemQuery reports whether an index entry passes the filter flags
+func matchesItemQuery(typ items.Type, e meta.IndexEntry) bool {
+	item := &items.Item{Type: typ, Status: e.Status, Priority: e.Priority, Labels: e.Labels}
+
+	if len(itemQuery.statuses) > 0 {
+		match := false
+		for _, status := range itemQuery.statuses {
+			match = match || status == e.Status || (status == statusClosed && item.IsClosed())
+		}
+		if !match {
+			return false
+		}
+	}
+	if len(itemQuery.priorities) > 0 {
+		match := false
+		for _, priority := range itemQuery.priorities {
+			match = match || strings.EqualFold(priority, e.Priority) || (priority == "none" && e.Priority == "")
+		}
+		if !match {
+			return false
+		}
+	}
+	for _, label := range itemQuery.labels {
+		if !item.HasLabel(label) {
+			return false
+		}
+	}
+	return itemQuery.sinceTime.IsZero() || !e.Updated.Before(itemQuery.sinceTime)
+}
+
+// indexDeps returns the dependencies between the indexed tasks
+func indexDeps(entries []meta.IndexEntry) items.Deps {
+	deps := make(items.Deps)
+	for _, e := range entries {
+		for _, blocker := range e.BlockedBy {
+			deps.Block(e.ID, blocker)
+		}
+		for _, blocked := range e.Blocks {
+			deps.Block(blocked, e.ID)
+		}
+	}
+	return deps
+}
+
+// waitingFor returns the tasks an indexed task waits for
+func waitingFor(e meta.IndexEntry, deps items.Deps, entries []meta.IndexEntry) []string {
+	return deps.Waiting(e.ID, func(id string) (bool, bool) {
+		for _, other := range entries {
+			if other.ID == id {
+				return (&items.Item{Type: items.TypeTask, Status: other.Status}).IsClosed(), true
+			}
+		}
+		return false, false
+	})
+}
+
+// isReady reports whether a task can be started: it is open, so not yet
+// worked on, reviewed, blocked or closed, and waits for no other task
+func isReady(e meta.IndexEntry, deps items.Deps, entries []meta.IndexEntry) bool {
+	return e.Status == items.StatusOpen && len(waitingFor(e, deps, entries)) == 0
+}
+
+// printTaskGraph prints the tasks as a tree: below each task the tasks
+// that wait for it. Tasks waiting for several tasks appear below each.
+func printTaskGraph(tasks []meta.IndexEntry) {
+	deps := indexDeps(tasks)
+	byID := make(map[string]meta.IndexEntry)
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	printed := make(map[string]bool)
+	var printTree func(t meta.IndexEntry, depth int, path map[string]bool)
+	printTree = func(t meta.IndexEntry, depth int, path map[string]bool) {
+		prefix := "  "
+		if depth > 0 {
+			prefix += strings.Repeat("   ", depth-1) + "└─ "
+		}
+		line := fmt.Sprintf("%s[%s] %s - %s", prefix, t.Status, t.ID, t.Title)
+		if printed[t.ID] {
+			fmt.Println(line + " (see above)")
+			return
+		}
+		fmt.Println(line)
+		printed[t.ID] = true
+
+		path[t.ID] = true
+		for _, id := range deps.Dependents(t.ID) {
+			if next, ok := byID[id]; ok && !path[id] {
+				printTree(next, depth+1, path)
+			}
+		}
+		delete(path, t.ID)
+	}
+
+	// Roots wait for none of the listed tasks
+	for _, t := range tasks {
+		root := true
+		for _, blocker := range deps[t.ID] {
+			_, listed := byID[blocker]
+			root = root && !listed
+		}
+		if root {
+			printTree(t, 0, make(map[string]bool))
+		}
+	}
+	// Tasks on a cycle have no root above them
+	for _, t := range tasks {
+		if !printed[t.ID] {
+			printTree(t, 0, make(map[string]bool))
+		}
+	}
+
+	if cycle := deps.Cycle(); cycle != nil {
+		printError("Dependency cycle: " + strings.Join(cycle, " -> "))
+		printInfo("Run 'lm item unlink' to break it.")
+	}
+}
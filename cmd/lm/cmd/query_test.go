@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+)
+
+func TestIsReady(t *testing.T) {
+	entries := []meta.IndexEntry{
+		{ID: "task-001", Status: items.StatusDone},
+		{ID: "task-002", Status: items.StatusOpen, BlockedBy: []string{"task-001"}},
+		{ID: "task-003", Status: items.StatusInProgress},
+		{ID: "task-004", Status: items.StatusInReview},
+		{ID: "task-005", Status: items.StatusBlocked},
+		{ID: "task-006", Status: items.StatusOpen, BlockedBy: []string{"task-003"}},
+		{ID: "task-007", Status: items.StatusOpen},
+	}
+	deps := indexDeps(entries)
+
+	var ready []string
+	for _, e := range entries {
+		if isReady(e, deps, entries) {
+			ready = append(ready, e.ID)
+		}
+	}
+	// Tasks already being worked on or reviewed are not ready to start
+	if len(ready) != 2 || ready[0] != "task-002" || ready[1] != "task-007" {
+		t.Errorf("ready tasks = %v, want [task-002 task-007]", ready)
+	}
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var metaRemote string
+
+var metaPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push the META shadow branch to a remote",
+	Long: `Push the local laddermoon-meta branch to a remote so teammates can pull it.
+
+The push is refused when the remote has META changes that were not pulled yet.
+
+Example:
+  lm meta push
+  lm meta push --remote upstream`,
+	Args: cobra.NoArgs,
+	RunE: runMetaPush,
+}
+
+var metaPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Fetch and merge the META shadow branch from a remote",
+	Long: `Fetch the remote laddermoon-meta branch and merge it into the local one.
+In a fresh clone the local branch is simply created from the remote one.
+
+Files are merged according to their type:
+- UserFeed.log is merged append-only; feeds added locally are renumbered
+  to follow the remote ones, and their [Feed #N] citations are updated
+- .next_feed_id is recomputed after renumbering
+- .sync_state keeps the newer code commit
+- META.md and item files are merged per file; conflicting merges are
+  shown for review before anything is written
+
+Example:
+  lm meta pull
+  lm meta pull --remote upstream`,
+	Args: cobra.NoArgs,
+	RunE: runMetaPull,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{metaPushCmd, metaPullCmd} {
+		c.Flags().StringVar(&metaRemote, "remote", meta.DefaultRemote, "Remote to sync the META branch with")
+		metaCmd.AddCommand(c)
+	}
+}
+
+// requireShadowStore checks prerequisites of commands that work on the
+// shadow branch itself and returns the current branch's shadow store
+func requireShadowStore(ctx context.Context) (*meta.ShadowStore, error) {
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return nil, err
+	}
+
+	store, err := openStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shadow, ok := store.(*meta.ShadowStore)
+	if !ok {
+		printError("This command only works with the shadow branch META store.")
+		return nil, fmt.Errorf("unsupported META store: %s", store.Location())
+	}
+	return shadow, nil
+}
+
+func runMetaPush(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := requireShadowStore(ctx); err != nil {
+		return err
+	}
+
+	if !meta.IsInitialized(ctx) {
+		printError("LadderMoon is not initialized. Run 'lm init' first.")
+		return meta.ErrNotInitialized
+	}
+
+	printInfo(fmt.Sprintf("Pushing META to %s...", metaRemote))
+	if err := meta.PushMeta(ctx, metaRemote); err != nil {
+		printError("Failed to push META: " + err.Error())
+		return err
+	}
+
+	printSuccess("META pushed!")
+	return nil
+}
+
+func runMetaPull(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := requireShadowStore(ctx); err != nil {
+		return err
+	}
+
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	printInfo(fmt.Sprintf("Fetching META from %s...", metaRemote))
+	merge, err := meta.PrepareMerge(ctx, metaRemote)
+	if err != nil {
+		if errors.Is(err, meta.ErrNoRemoteMeta) {
+			printInfo(fmt.Sprintf("Remote %s has no META yet. Run 'lm meta push' to publish it.", metaRemote))
+			return nil
+		}
+		printError("Failed to fetch META: " + err.Error())
+		return err
+	}
+
+	if merge.UpToDate {
+		printInfo("Already up to date.")
+		return nil
+	}
+
+	if !merge.FastForward {
+		for _, dir := range sortedKeys(merge.Renumbered) {
+			mapping := merge.Renumbered[dir]
+			feeds := make([]int, 0, len(mapping))
+			for from := range mapping {
+				feeds = append(feeds, from)
+			}
+			sort.Ints(feeds)
+			for _, from := range feeds {
+				printInfo(fmt.Sprintf("Renumbered local Feed #%d to Feed #%d (%s)", from, mapping[from], dir))
+			}
+		}
+		for _, dir := range sortedKeys(merge.RenumberedItems) {
+			mapping := merge.RenumberedItems[dir]
+			for _, from := range sortedKeys(mapping) {
+				printInfo(fmt.Sprintf("Renumbered local %s to %s, the remote filed another item under its ID (%s)", from, mapping[from], dir))
+			}
+		}
+		for _, f := range merge.Files {
+			if f.Resolution != meta.NeedsReview {
+				printInfo(fmt.Sprintf("Merged %s (%s)", f.Path, f.Resolution))
+			}
+		}
+
+		for _, f := range merge.Conflicts() {
+			resolved, err := reviewMergedFile(ctx, f)
+			if err != nil {
+				printError(err.Error())
+				return err
+			}
+			merge.Resolve(f, resolved)
+		}
+	}
+
+	if err := meta.ApplyMerge(ctx, merge); err != nil {
+		printError("Failed to merge META: " + err.Error())
+		return err
+	}
+
+	if merge.FastForward {
+		printSuccess(fmt.Sprintf("META fast-forwarded to %s", shortCommit(merge.Remote)))
+	} else {
+		printSuccess("Remote META merged!")
+	}
+	return nil
+}
+
+// reviewMergedFile lets the user decide the final content of a conflicting file
+func reviewMergedFile(ctx context.Context, f *meta.MergedFile) (string, error) {
+	for {
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Printf("Conflict: %s\n", f.Path)
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Println(f.Merged)
+		fmt.Println(strings.Repeat("-", 60))
+
+		fmt.Println("Options:")
+		fmt.Println("  [e] Edit   - Resolve the merged text in $EDITOR")
+		fmt.Println("  [l] Local  - Keep the local version")
+		fmt.Println("  [r] Remote - Take the remote version")
+		fmt.Println("  [q] Quit   - Abort the pull, nothing is written")
+		fmt.Print("\nYour choice: ")
+
+		choice, err := readLine(ctx)
+		if err != nil {
+			return "", fmt.Errorf("pull aborted: %w", err)
+		}
+
+		switch strings.ToLower(choice) {
+		case "e", "edit":
+			edited, err := editText(f.Merged)
+			if err != nil {
+				printError("Failed to edit: " + err.Error())
+				continue
+			}
+			if strings.Contains(edited, "<<<<<<<") || strings.Contains(edited, ">>>>>>>") {
+				printError("Conflict markers are still present.")
+				f.Merged = edited
+				continue
+			}
+			return edited, nil
+		case "l", "local":
+			return f.Local, nil
+		case "r", "remote":
+			return f.Remote, nil
+		case "q", "quit":
+			return "", fmt.Errorf("pull aborted")
+		}
+	}
+}
+
+// editText opens text in the user's editor and returns the saved result
+func editText(text string) (string, error) {
+	tmp, err := os.CreateTemp("", "lm-edit-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(text); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	tmp.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", tmp.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
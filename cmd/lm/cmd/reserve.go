@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+)
+
+// reservedIDs is how many IDs a skill that files items gets. The ones it
+// doesn't use are given back afterwards.
+const reservedIDs = 10
+
+// reserveItemIDs reserves IDs of an item type for a skill, under the META
+// lock
+func reserveItemIDs(ctx context.Context, store meta.MetaStore, typ items.Type) ([]string, error) {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	ids, err := meta.ReserveItemIDs(store, typ, reservedIDs)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to reserve %s IDs: %s", typ, err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// settleItems gives back the reserved IDs a skill didn't use and brings
+// the index of the type up to date with the items the skill filed, under
+// the META lock. The command's work is done by then, so a failure is only
+// reported, not returned: it costs a gap in the IDs or a stale index that
+// 'lm index rebuild' repairs.
+func settleItems(ctx context.Context, store meta.MetaStore, typ items.Type, ids []string) {
+	if ctx.Err() != nil {
+		return
+	}
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return
+	}
+	defer lock.Release()
+
+	if err := meta.ReleaseItemIDs(store, typ, ids); err != nil {
+		printInfo(fmt.Sprintf("Unused %s IDs were not given back: %s", typ, err))
+	}
+	if _, err := meta.RebuildIndexes(store, typ); err != nil {
+		printInfo(fmt.Sprintf("The %s index was not updated: %s", typ, err))
+	}
+}
+
+// describeItemIDs tells a skill which IDs to file items of a type under
+func describeItemIDs(typ items.Type, ids []string) string {
+	return fmt.Sprintf("Reserved %s IDs: %s. File new %ss under these IDs, in this order, and never choose an ID yourself.",
+		typ, strings.Join(ids, ", "), typ)
+}
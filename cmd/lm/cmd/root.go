@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
 )
 
@@ -14,14 +19,69 @@ var rootCmd = &cobra.Command{
 	
 Core concept: "AI AS ME" - Let AI become your shadow self,
 learning your architectural preferences and decision patterns.`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		commandRan = true
+	},
 }
 
+var storeKind string
+
 func Execute() error {
-	return rootCmd.Execute()
+	// Agents started by this invocation tag their META commits with the same session
+	os.Setenv(meta.SessionEnv, meta.SessionID())
+	defer meta.CloseReader()
+
+	// SIGINT and SIGTERM cancel the context instead of killing lm, so
+	// agents are stopped, staged META changes are dropped and worktrees
+	// and the META lock are released on the way out. A second signal
+	// kills lm right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil {
+		reportError(err)
+	}
+	return err
 }
 
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().StringVar(&storeKind, "store", "", "META store: shadow, dir or memory (default: git config laddermoon.store, else shadow)")
+}
+
+// openStore opens the META store selected by --store or git config.
+// A shadow branch created before the branch registry is migrated first.
+func openStore(ctx context.Context) (meta.MetaStore, error) {
+	store, err := meta.OpenStore(ctx, storeKind)
+	if err != nil {
+		if errors.Is(err, meta.ErrDetachedHead) {
+			printError("HEAD is detached. LadderMoon keeps META per branch, check out a branch first.")
+			return nil, err
+		}
+		printError("Failed to open META store: " + err.Error())
+		return nil, err
+	}
+
+	if _, ok := store.(*meta.ShadowStore); ok && meta.NeedsMigration(ctx) {
+		printInfo("Migrating META branch directories to the branch registry...")
+		if err := migrateBranchDirs(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if store.Initialized() && meta.HasLegacyProposals(store) {
+		printInfo("Moving proposals filed by an older version to Proposals/...")
+		if err := migrateLegacyProposals(ctx, store); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
 }
 
 func printSuccess(msg string) {
@@ -29,9 +89,14 @@ func printSuccess(msg string) {
 }
 
 func printError(msg string) {
+	errorReported = true
 	fmt.Fprintf(os.Stderr, "[LadderMoon] Error: %s\n", msg)
 }
 
 func printInfo(msg string) {
 	fmt.Fprintf(os.Stdout, "[LadderMoon] %s\n", msg)
 }
+
+func printWarning(msg string) {
+	fmt.Fprintf(os.Stderr, "[LadderMoon] Warning: %s\n", msg)
+}
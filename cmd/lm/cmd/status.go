@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/laddermoon/laddermoon/pkg/meta"
@@ -24,28 +25,22 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check if we're in a git repository
-	_, err := meta.GetGitRoot()
+	_, err := meta.GetGitRoot(ctx)
 	if err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
-	// Check if initialized
-	if !meta.IsInitialized() {
-		printError("LadderMoon is not initialized.")
-		printInfo("Run 'lm init' to initialize.")
-		return meta.ErrNotInitialized
-	}
-
-	// Check if current branch is initialized
-	branchInitialized, err := meta.BranchMetaDirExists()
+	store, err := openStore(ctx)
 	if err != nil {
-		printError("Failed to check branch status: " + err.Error())
 		return err
 	}
 
-	if !branchInitialized {
+	// Check if current branch is initialized
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized for this branch.")
 		printInfo("Run 'lm init' to initialize for this branch.")
 		return meta.ErrNotInitialized
@@ -55,32 +50,40 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	// Get current commit ID
-	currentCommit, err := meta.GetCurrentCommitID()
+	currentCommit, err := meta.GetCurrentCommitID(ctx)
 	if err != nil {
 		printError("Failed to get current commit: " + err.Error())
 		return err
 	}
 
-	// Get META branch commit ID
-	metaCommit, err := meta.GetMetaBranchCommitID()
+	// Get latest META revision
+	history, err := store.History("")
 	if err != nil {
-		printError("Failed to get META branch commit: " + err.Error())
+		printError("Failed to get META history: " + err.Error())
 		return err
 	}
+	metaCommit := ""
+	if len(history) > 0 {
+		metaCommit = history[0].ID
+	}
 
 	// Get synced commit ID
-	syncedCommit, _ := meta.GetSyncedCommitID()
+	syncedCommit, err := meta.GetSyncedCommitID(store)
+	if err != nil {
+		printError("Failed to read sync state: " + err.Error())
+		return err
+	}
 
 	// Get META file list
-	files, err := meta.GetMetaFileList()
+	files, err := store.List()
 	if err != nil {
 		printError("Failed to list META files: " + err.Error())
 		return err
 	}
 
 	// Read META.md content
-	metaContent, err := meta.ReadMetaFile()
-	if err != nil {
+	metaContent, err := store.Read(meta.MetaFileName)
+	if err != nil && !errors.Is(err, meta.ErrNotFound) {
 		printError("Failed to read META.md: " + err.Error())
 		return err
 	}
@@ -92,16 +95,22 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	// Get current branch
-	currentBranch, _ := meta.GetCurrentBranch()
+	currentBranch, err := meta.GetCurrentBranch(ctx)
+	if err != nil {
+		currentBranch = "(detached HEAD)"
+	}
 
 	fmt.Printf("  %-20s %s\n", "Initialized:", "✓ Yes")
 	fmt.Printf("  %-20s %s\n", "Current Branch:", currentBranch)
-	fmt.Printf("  %-20s %s\n", "META Branch:", meta.BranchName)
+	fmt.Printf("  %-20s %s\n", "META Store:", store.Location())
 	fmt.Printf("  %-20s %s\n", "Main Commit:", shortCommit(currentCommit))
 	fmt.Printf("  %-20s %s\n", "META Commit:", shortCommit(metaCommit))
+	if fork, _ := meta.ReadForkPoint(store); fork != nil {
+		fmt.Printf("  %-20s %s (META %s)\n", "Forked From:", fork.Parent, shortCommit(fork.MetaCommit))
+	}
 
 	// Skills status
-	if meta.SkillsInstalled() {
+	if meta.SkillsInstalled(ctx) {
 		fmt.Printf("  %-20s %s\n", "Skills:", "✓ Installed")
 	} else {
 		fmt.Printf("  %-20s %s\n", "Skills:", "⚠ Not installed")
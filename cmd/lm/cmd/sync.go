@@ -1,9 +1,8 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
@@ -19,6 +18,11 @@ This command invokes the laddermoon-sync skill via Claude Code to:
 2. Analyze the changes and update META.md appropriately
 3. Update the sync state
 
+After a rebase or force-push the last synced commit is no longer part of
+the history. Sync then starts from the merge base of the old and new
+history and tells the agent which commits were rewritten, added or
+dropped. The sync commit records both lineages.
+
 After sync, you can run 'lm audit' or 'lm propose' to analyze the changes.`,
 	RunE: runSync,
 }
@@ -28,60 +32,69 @@ func init() {
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check if we're in a git repository
-	_, err := meta.GetGitRoot()
+	_, err := meta.GetGitRoot(ctx)
 	if err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
 	// Check if initialized
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized.")
 		printInfo("Run 'lm init' to initialize.")
 		return meta.ErrNotInitialized
 	}
 
 	// Check if skills are installed
-	if !meta.SkillsInstalled() {
+	if !meta.SkillsInstalled(ctx) {
 		printError("LadderMoon skills are not installed.")
 		printInfo("Run 'lm init' to reinstall.")
 		return fmt.Errorf("skills not installed")
 	}
 
 	// Get current commit
-	currentCommit, err := meta.GetCurrentCommitID()
+	currentCommit, err := meta.GetCurrentCommitID(ctx)
 	if err != nil {
 		printError("Failed to get current commit: " + err.Error())
 		return err
 	}
 
-	// Get last synced commit
-	lastSyncedCommit, _ := meta.GetSyncedCommitID()
+	// Work out what changed since the last sync, following rewritten history
+	syncRange, err := meta.ComputeSyncRange(ctx, store, currentCommit)
+	if err != nil {
+		printError("Failed to compare with the last synced commit: " + err.Error())
+		return err
+	}
 
-	if lastSyncedCommit == currentCommit {
+	if syncRange.UpToDate() {
 		printInfo("Already up to date. No changes since last sync.")
 		return nil
 	}
 
 	printInfo("Synchronizing codebase changes with AI...")
-	if lastSyncedCommit != "" {
-		printInfo(fmt.Sprintf("Changes: %s → %s", shortCommit(lastSyncedCommit), shortCommit(currentCommit)))
-	} else {
-		printInfo("First sync - analyzing current state...")
-	}
+	printSyncRange(syncRange)
 
 	// Invoke Claude Code with the laddermoon-sync skill
-	if err := invokeSyncSkill(); err != nil {
+	if err := invokeSyncSkill(ctx, syncRange); err != nil {
 		printError("Failed to sync: " + err.Error())
-		printInfo("Make sure 'claude' CLI is installed and configured.")
+		if !interrupted(err) {
+			printInfo("Make sure 'claude' CLI is installed and configured.")
+		}
 		return err
 	}
 
 	// After skill completes, update sync state
 	printInfo("")
 	printInfo("Updating sync state...")
-	if err := meta.SetSyncedCommitID(currentCommit); err != nil {
+	if err := recordSync(ctx, store, syncRange); err != nil {
 		printError("Failed to update sync state: " + err.Error())
 		return err
 	}
@@ -93,13 +106,40 @@ func runSync(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func invokeSyncSkill() error {
-	prompt := "Use the laddermoon-sync skill to sync repository changes to META."
+// recordSync records the synced range under the META lock, which is not
+// held while the skill runs
+func recordSync(ctx context.Context, store meta.MetaStore, r *meta.SyncRange) error {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	return meta.RecordSync(store, r)
+}
+
+// printSyncRange shows the range about to be synced
+func printSyncRange(r *meta.SyncRange) {
+	if r.Lost != "" {
+		printInfo(fmt.Sprintf("Last synced commit %s no longer exists.", shortCommit(r.Lost)))
+	}
+	switch {
+	case r.From == "":
+		printInfo("First sync - analyzing current state...")
+	case !r.Rewritten:
+		printInfo(fmt.Sprintf("Changes: %s → %s (%d commits)", shortCommit(r.From), shortCommit(r.To), len(r.Added)))
+	default:
+		base := "no common commit"
+		if r.Base != "" {
+			base = "merge base " + shortCommit(r.Base)
+		}
+		printInfo(fmt.Sprintf("History was rewritten: %s → %s (%s)", shortCommit(r.From), shortCommit(r.To), base))
+		printInfo(fmt.Sprintf("  %d rewritten, %d new, %d dropped commits", len(r.Rewrites), len(r.Added), len(r.Dropped)))
+	}
+}
 
-	cmd := exec.Command("claude", "-p", prompt, "--dangerously-skip-permissions")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+func invokeSyncSkill(ctx context.Context, r *meta.SyncRange) error {
+	prompt := "Use the laddermoon-sync skill to sync repository changes to META.\n\n" + r.Describe()
 
-	return cmd.Run()
+	return runAgent(ctx, false, "-p", prompt, "--dangerously-skip-permissions")
 }
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+	"github.com/laddermoon/laddermoon/pkg/meta"
+)
+
+// triageItems shows each item to the user, with the open and rejected
+// items it likely repeats, and records what they decide about it in the
+// decision log. noun is what the items are called.
+func triageItems(ctx context.Context, store meta.MetaStore, open []*items.Item, noun string) error {
+	title := strings.ToUpper(noun[:1]) + noun[1:]
+	if len(open) == 0 {
+		return nil
+	}
+
+	candidates, err := duplicateCandidates(store, open[0].Type)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to load %ss: %s", noun, err))
+		return err
+	}
+	corpus := items.NewCorpus(candidates)
+
+	for _, item := range open {
+		// Display item content
+		content, err := store.Read(item.Path)
+		if err != nil || content == "" {
+			continue
+		}
+
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Printf("%s: %s\n", title, item.Path)
+		fmt.Println(strings.Repeat("=", 60))
+		fmt.Println(content)
+		fmt.Println(strings.Repeat("-", 60))
+
+		duplicates := corpus.Similar(item, items.DuplicateThreshold)
+		if len(duplicates) > 0 {
+			fmt.Println("Possible duplicates:")
+			for _, m := range duplicates {
+				fmt.Printf("  [%s] %s - %s (%.0f%% similar)\n", m.Item.Status, m.Item.ID, m.Item.Title(), m.Score*100)
+			}
+			fmt.Println(strings.Repeat("-", 60))
+		}
+
+		fmt.Println("Options:")
+		fmt.Printf("  [a] Approve - Create a Task for this %s\n", noun)
+		fmt.Printf("  [r] Reject  - Not a valid %s\n", noun)
+		fmt.Println("  [s] Skip    - Decide later")
+		fmt.Println("  [q] Quit    - Stop reviewing")
+		fmt.Print("\nYour choice: ")
+
+		answer, err := readLine(ctx)
+		if err != nil {
+			return err
+		}
+
+		var choice string
+		switch strings.ToLower(answer) {
+		case "a", "approve":
+			choice = meta.ChoiceApprove
+		case "r", "reject":
+			choice = meta.ChoiceReject
+		case "s", "skip":
+			choice = meta.ChoiceSkip
+		case "q", "quit":
+			printInfo("Stopped reviewing.")
+			return nil
+		default:
+			fmt.Println()
+			continue
+		}
+
+		priority := item.Priority
+		if choice == meta.ChoiceApprove {
+			if priority, err = readPriority(ctx, priority); err != nil {
+				return err
+			}
+		}
+
+		var reason string
+		if choice == meta.ChoiceReject && len(duplicates) > 0 {
+			duplicateOf := "duplicate of " + duplicates[0].Item.ID
+			fmt.Printf("Reason (Enter: %s): ", duplicateOf)
+			if reason, err = readLine(ctx); err != nil {
+				return err
+			}
+			if reason == "" {
+				reason = duplicateOf
+			}
+		} else {
+			fmt.Print("Reason (optional): ")
+			if reason, err = readLine(ctx); err != nil {
+				return err
+			}
+		}
+
+		task, err := triageItem(ctx, store, item, choice, priority, reason)
+		if err != nil {
+			return err
+		}
+		switch choice {
+		case meta.ChoiceApprove:
+			printSuccess(fmt.Sprintf("Task created: %s (%s)", task.ID, task.Path))
+			if task.Priority != "" {
+				printInfo("Priority: " + task.Priority)
+			}
+			printInfo("Run 'lm workon " + task.ID + "' to start working on it.")
+		case meta.ChoiceReject:
+			printInfo(title + " rejected.")
+		case meta.ChoiceSkip:
+			printInfo("Skipped.")
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// readPriority asks for the priority of an approved item, keeping current
+// on an empty answer
+func readPriority(ctx context.Context, current string) (string, error) {
+	keep := current
+	if keep == "" {
+		keep = "none"
+	}
+	for {
+		fmt.Printf("Priority (%s, Enter keeps %s): ", strings.Join(items.Priorities, "/"), keep)
+		answer, err := readLine(ctx)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return current, nil
+		}
+		priority, err := items.ParsePriority(answer)
+		if err == nil {
+			return priority, nil
+		}
+		printError(err.Error())
+	}
+}
+
+// triageItem records the user's choice about an item and applies it, under
+// the META lock. An approved item gets priority, which its task takes over.
+// It returns the task an approval created.
+func triageItem(ctx context.Context, store meta.MetaStore, item *items.Item, choice, priority, reason string) (*items.Item, error) {
+	lock, err := acquireMetaLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	// The item may have changed while the user was reading it
+	current, err := items.Load(store, item.Path)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to read %s: %s", item.ID, err))
+		return nil, err
+	}
+
+	if choice == meta.ChoiceApprove {
+		current.Priority = priority
+	}
+	task, err := meta.Triage(ctx, store, current, choice, meta.GetGitUser(ctx), reason)
+	if err != nil {
+		printError(fmt.Sprintf("Failed to record decision on %s: %s", item.ID, err))
+		return nil, err
+	}
+	return task, nil
+}
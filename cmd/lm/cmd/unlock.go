@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/laddermoon/laddermoon/pkg/meta"
+	"github.com/spf13/cobra"
+)
+
+var forceUnlock bool
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Clear a stale or orphaned META lock",
+	Long: `Clear the META lock file (.lm.lock) left behind by an interrupted command.
+
+Without --force only a lock that is no longer held is cleared. With --force
+a held lock is broken as well, unless its holder is a running process on
+this host; stop that process first.
+
+The lock wait timeout defaults to 30s and can be changed with the
+LM_LOCK_TIMEOUT environment variable or 'git config laddermoon.lockTimeout'.
+
+Example:
+  lm unlock
+  lm unlock --force`,
+	Args: cobra.NoArgs,
+	RunE: runUnlock,
+}
+
+func init() {
+	unlockCmd.Flags().BoolVar(&forceUnlock, "force", false, "Break the lock even if it is still held")
+	rootCmd.AddCommand(unlockCmd)
+}
+
+func runUnlock(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if _, err := meta.GetGitRoot(ctx); err != nil {
+		printError("This command must be run inside a Git repository.")
+		return err
+	}
+
+	holder, err := meta.Unlock(ctx, forceUnlock)
+	switch {
+	case errors.Is(err, meta.ErrLockHeld):
+		printError("META lock is held by " + describeHolder(holder) + ".")
+		printInfo("Use 'lm unlock --force' if that process is hung or gone.")
+		return err
+	case errors.Is(err, meta.ErrLockHolderUp):
+		printError("META lock holder is still running: " + holder.Describe())
+		printInfo(fmt.Sprintf("Stop process %d first, then run 'lm unlock --force' again.", holder.PID))
+		return err
+	case err != nil:
+		printError("Failed to clear META lock: " + err.Error())
+		return err
+	}
+
+	if holder == nil {
+		printSuccess("META lock is free.")
+	} else {
+		printSuccess("Cleared META lock of " + holder.Describe())
+	}
+	return nil
+}
+
+// acquireMetaLock acquires the META lock, reporting who holds it while waiting
+func acquireMetaLock(ctx context.Context) (*meta.MetaLock, error) {
+	printInfo("Acquiring META lock...")
+	lock, err := meta.AcquireMetaLockTimeout(ctx, meta.LockTimeout(ctx), func(holder *meta.LockHolder) {
+		printInfo("Waiting for META lock held by " + holder.Describe())
+	})
+	if err != nil {
+		printError("Failed to acquire lock: " + err.Error())
+		var held *meta.LockHeldError
+		if errors.As(err, &held) && !held.Orphan {
+			printInfo("Run 'lm unlock' if the holder is gone.")
+		}
+		return nil, err
+	}
+	if lock.Recovered != nil {
+		printInfo("Recovered stale META lock of " + lock.Recovered.Describe())
+	}
+	return lock, nil
+}
+
+func describeHolder(holder *meta.LockHolder) string {
+	if holder == nil {
+		return "an unknown process"
+	}
+	return holder.Describe()
+}
@@ -1,11 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/laddermoon/laddermoon/pkg/items"
 	"github.com/laddermoon/laddermoon/pkg/meta"
 	"github.com/spf13/cobra"
 )
@@ -21,52 +21,82 @@ This command orchestrates:
 2. Review: Review the changes (laddermoon-review skill)
 3. Apply: Merge the feature branch (laddermoon-apply skill)
 
+The task is a Task ID or path from 'lm tasks', or a free-text description.
+A Task that is blocked, or waits for Tasks that are not done yet, is
+refused unless --force is given; 'lm tasks --ready' lists the ones to pick.
+
 Example:
-  lm workon Tasks/task-from-issue-001.md
+  lm workon task-001
   lm workon "Add user authentication"
-  lm solve Tasks/task-001.md`,
+  lm solve Tasks/task-001-add-login.md
+  lm workon task-003 --force      # Start even though it is blocked`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runWorkon,
 }
 
+var workonForce bool
+
 func init() {
+	workonCmd.Flags().BoolVar(&workonForce, "force", false, "Work on the task even if it is blocked")
 	rootCmd.AddCommand(workonCmd)
 }
 
 func runWorkon(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check prerequisites
-	if _, err := meta.GetGitRoot(); err != nil {
+	if _, err := meta.GetGitRoot(ctx); err != nil {
 		printError("This command must be run inside a Git repository.")
 		return err
 	}
 
-	if !meta.IsInitialized() {
+	store, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !store.Initialized() {
 		printError("LadderMoon is not initialized. Run 'lm init' first.")
 		return meta.ErrNotInitialized
 	}
 
-	if !meta.SkillsInstalled() {
+	if !meta.SkillsInstalled(ctx) {
 		printError("LadderMoon skills are not installed.")
 		printInfo("Run 'lm init' to reinstall.")
 		return fmt.Errorf("skills not installed")
 	}
 
+	// Input that names no task is passed on as a free-form description
 	taskInput := strings.Join(args, " ")
+	task, err := items.Find(store, taskInput)
+	if err = skipBrokenItems(err); err != nil {
+		printError(fmt.Sprintf("Failed to look up %s: %s", taskInput, err))
+		return err
+	}
+	if task != nil && task.Type == items.TypeTask {
+		blocked, err := checkTaskReady(store, task)
+		if err != nil {
+			return err
+		}
+		taskInput = describeTask(task, blocked)
+	}
 
 	// Step 1: Code - implement the task
 	printInfo("\n=== Step 1: Code ===")
 	printInfo("Task: " + taskInput)
 	printInfo("Starting implementation...")
 
-	if err := invokeCodeSkill(taskInput); err != nil {
+	if err := invokeCodeSkill(ctx, taskInput); err != nil {
 		printError("Code step failed: " + err.Error())
 		return err
 	}
 
 	// Ask user if they want to continue to review
 	fmt.Print("\nContinue to Review? [y/n]: ")
-	var choice string
-	fmt.Scanln(&choice)
+	choice, err := readLine(ctx)
+	if err != nil {
+		return err
+	}
 	if strings.ToLower(choice) != "y" {
 		printInfo("Stopped after Code step. Run 'lm workon' again to continue.")
 		return nil
@@ -76,14 +106,16 @@ func runWorkon(cmd *cobra.Command, args []string) error {
 	printInfo("\n=== Step 2: Review ===")
 	printInfo("Reviewing changes...")
 
-	if err := invokeReviewSkill(); err != nil {
+	if err := invokeReviewSkill(ctx); err != nil {
 		printError("Review step failed: " + err.Error())
 		return err
 	}
 
 	// Ask user if they want to continue to apply
 	fmt.Print("\nReview passed. Apply changes (merge)? [y/n]: ")
-	fmt.Scanln(&choice)
+	if choice, err = readLine(ctx); err != nil {
+		return err
+	}
 	if strings.ToLower(choice) != "y" {
 		printInfo("Stopped after Review step. Changes are in the feature branch.")
 		return nil
@@ -93,7 +125,7 @@ func runWorkon(cmd *cobra.Command, args []string) error {
 	printInfo("\n=== Step 3: Apply ===")
 	printInfo("Merging changes...")
 
-	if err := invokeApplySkill(); err != nil {
+	if err := invokeApplySkill(ctx); err != nil {
 		printError("Apply step failed: " + err.Error())
 		printInfo("You may need to resolve merge conflicts manually.")
 		return err
@@ -104,36 +136,59 @@ func runWorkon(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func invokeCodeSkill(taskInput string) error {
+// checkTaskReady refuses a task that is blocked or waits for other tasks,
+// unless --force is given. It returns why a task started with --force is
+// blocked, "" for a ready task.
+func checkTaskReady(store meta.MetaStore, task *items.Item) (string, error) {
+	tasks, err := items.LoadAll(store, items.TypeTask)
+	if err = skipBrokenItems(err); err != nil {
+		printError("Failed to load tasks: " + err.Error())
+		return "", err
+	}
+	waiting := items.OpenBlockers(tasks, task.ID)
+	if len(waiting) == 0 && task.Status != items.StatusBlocked {
+		return "", nil
+	}
+
+	reason := "its status is " + items.StatusBlocked
+	if len(waiting) > 0 {
+		reason = "it waits for " + strings.Join(waiting, ", ")
+	}
+	if workonForce {
+		printInfo(fmt.Sprintf("%s is blocked: %s. Continuing because of --force.", task.ID, reason))
+		return reason, nil
+	}
+	printError(fmt.Sprintf("%s is blocked: %s.", task.ID, reason))
+	printInfo("Run 'lm tasks --ready' for tasks that can be started, or use --force.")
+	return "", fmt.Errorf("%w: %s", items.ErrTaskBlocked, task.ID)
+}
+
+// describeTask tells the code skill where to find a task. blocked is why
+// the task is blocked when the user started it with --force; the skill is
+// told so, or it would stop at the blockers itself.
+func describeTask(task *items.Item, blocked string) string {
+	input := fmt.Sprintf("%s (%s in the META directory of this branch on laddermoon-meta): %s", task.ID, task.Path, task.Title())
+	if blocked != "" {
+		input += fmt.Sprintf("\n\nThe user chose to work on this task anyway, although it is blocked (%s). Don't stop because of its blockers.", blocked)
+	}
+	return input
+}
+
+func invokeCodeSkill(ctx context.Context, taskInput string) error {
 	prompt := fmt.Sprintf("Use the laddermoon-code skill to implement this task: %s", taskInput)
 
 	// Coding needs interaction
-	cmd := exec.Command("claude", prompt)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return runAgent(ctx, true, prompt)
 }
 
-func invokeReviewSkill() error {
+func invokeReviewSkill(ctx context.Context) error {
 	prompt := "Use the laddermoon-review skill to review the changes in the current feature branch."
 
-	cmd := exec.Command("claude", prompt)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return runAgent(ctx, true, prompt)
 }
 
-func invokeApplySkill() error {
+func invokeApplySkill(ctx context.Context) error {
 	prompt := "Use the laddermoon-apply skill to merge the current feature branch into main. If there are conflicts, try to resolve them."
 
-	cmd := exec.Command("claude", prompt)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return runAgent(ctx, true, prompt)
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+func TestDescribeTask(t *testing.T) {
+	task := &items.Item{
+		ID:   "task-003",
+		Type: items.TypeTask,
+		Path: "Tasks/task-003-parser.md",
+		Body: "# Task: Fix the parser\n",
+	}
+
+	ready := describeTask(task, "")
+	if !strings.Contains(ready, "task-003 (Tasks/task-003-parser.md") || !strings.Contains(ready, "Fix the parser") {
+		t.Errorf("describeTask(ready) = %q, want the ID, path and title", ready)
+	}
+	if strings.Contains(ready, "chose to work on this task anyway") {
+		t.Errorf("describeTask(ready) = %q, want no note on blockers", ready)
+	}
+
+	forced := describeTask(task, "it waits for task-001, task-002")
+	if !strings.Contains(forced, "The user chose to work on this task anyway") || !strings.Contains(forced, "task-001, task-002") {
+		t.Errorf("describeTask(forced) = %q, want a note naming the blockers", forced)
+	}
+}
@@ -0,0 +1,52 @@
+package items
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DiscussionSection is the body section comments are appended to
+const DiscussionSection = "Discussion"
+
+// AddComment appends a comment by author to the Discussion section of the
+// body, creating the section at the end if the item has none. Headings in
+// the text are demoted below the comment's own heading so they can't end
+// the section.
+func (i *Item) AddComment(author, text string, at time.Time) {
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "### %s (%s)\n\n", author, at.UTC().Format(time.RFC3339))
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.HasPrefix(line, "#") {
+			line = "###" + line
+		}
+		entry.WriteString(line + "\n")
+	}
+
+	lines := strings.Split(strings.TrimRight(i.Body, "\n"), "\n")
+	start := -1
+	for n, line := range lines {
+		if heading, ok := strings.CutPrefix(line, "## "); ok && strings.EqualFold(strings.TrimSpace(heading), DiscussionSection) {
+			start = n
+			break
+		}
+	}
+	if start < 0 {
+		i.Body = strings.Join(lines, "\n") + "\n\n## " + DiscussionSection + "\n\n" + entry.String()
+		return
+	}
+
+	end := len(lines)
+	for n := start + 1; n < len(lines); n++ {
+		if strings.HasPrefix(lines[n], "## ") || strings.HasPrefix(lines[n], "# ") {
+			end = n
+			break
+		}
+	}
+	before := strings.TrimRight(strings.Join(lines[:end], "\n"), "\n")
+	body := before + "\n\n" + entry.String()
+	if end < len(lines) {
+		body += "\n" + strings.Join(lines[end:], "\n") + "\n"
+	}
+	i.Body = body
+}
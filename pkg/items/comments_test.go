@@ -0,0 +1,50 @@
+package items
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddComment(t *testing.T) {
+	at := time.Date(2025, 4, 2, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		body string
+		text string
+		want string
+	}{
+		{
+			name: "new section",
+			body: "# Issue: Slow\n\n## Description\n\nIt's slow.\n",
+			text: "Confirmed on main.",
+			want: "# Issue: Slow\n\n## Description\n\nIt's slow.\n\n## Discussion\n\n### Ada (2025-04-02T08:00:00Z)\n\nConfirmed on main.\n",
+		},
+		{
+			name: "last section",
+			body: "# Issue: Slow\n\n## Discussion\n\n### Bob (2025-04-01T08:00:00Z)\n\nFirst.\n",
+			text: "Second.",
+			want: "# Issue: Slow\n\n## Discussion\n\n### Bob (2025-04-01T08:00:00Z)\n\nFirst.\n\n### Ada (2025-04-02T08:00:00Z)\n\nSecond.\n",
+		},
+		{
+			name: "section followed by another",
+			body: "# Issue: Slow\n\n## discussion\n\n### Bob (2025-04-01T08:00:00Z)\n\nFirst.\n\n## Resolution\n\nFixed.\n",
+			text: "Second.",
+			want: "# Issue: Slow\n\n## discussion\n\n### Bob (2025-04-01T08:00:00Z)\n\nFirst.\n\n### Ada (2025-04-02T08:00:00Z)\n\nSecond.\n\n## Resolution\n\nFixed.\n",
+		},
+		{
+			name: "headings demoted",
+			body: "# Issue: Slow\n",
+			text: "\n# Plan\n## Steps\nProfile it.\n\n",
+			want: "# Issue: Slow\n\n## Discussion\n\n### Ada (2025-04-02T08:00:00Z)\n\n#### Plan\n##### Steps\nProfile it.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &Item{ID: "issue-001", Type: TypeIssue, Body: tt.body}
+			item.AddComment("Ada", tt.text, at)
+			if item.Body != tt.want {
+				t.Errorf("AddComment() body =\n%q\nwant\n%q", item.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package items
+
+import (
+	"errors"
+	"sort"
+)
+
+// Link relations between a task and the tasks that must be done before it
+const (
+	LinkBlocks    = "blocks"
+	LinkBlockedBy = "blocked-by"
+)
+
+var (
+	// ErrDependencyCycle is returned for a dependency that would make a
+	// task wait for itself
+	ErrDependencyCycle = errors.New("dependency cycle")
+	// ErrNotTask is returned for a dependency on or of an item that is not
+	// a task
+	ErrNotTask = errors.New("only tasks have dependencies")
+	// ErrTaskBlocked is returned for starting a task that waits for other
+	// tasks
+	ErrTaskBlocked = errors.New("task is blocked")
+)
+
+// Deps maps task IDs to the IDs of the tasks blocking them
+type Deps map[string][]string
+
+// Block records that blocker must be done before id
+func (d Deps) Block(id, blocker string) {
+	for _, b := range d[id] {
+		if b == blocker {
+			return
+		}
+	}
+	d[id] = append(d[id], blocker)
+}
+
+// TaskDeps returns the dependencies the tasks declare. A dependency counts
+// whether it is linked as blocks on the blocker, as blocked-by on the
+// blocked task, or both.
+func TaskDeps(tasks []*Item) Deps {
+	d := make(Deps)
+	for _, task := range tasks {
+		for _, blocker := range task.Linked(LinkBlockedBy) {
+			d.Block(task.ID, blocker)
+		}
+		for _, blocked := range task.Linked(LinkBlocks) {
+			d.Block(blocked, task.ID)
+		}
+	}
+	return d
+}
+
+// Waiting returns the blockers of id that are not done yet, in order.
+// closed reports whether the task with an ID is closed; blockers that
+// don't exist don't hold a task up.
+func (d Deps) Waiting(id string, closed func(id string) (done, exists bool)) []string {
+	var waiting []string
+	for _, b := range d[id] {
+		if isClosed, exists := closed(b); exists && !isClosed {
+			waiting = append(waiting, b)
+		}
+	}
+	sort.Strings(waiting)
+	return waiting
+}
+
+// OpenBlockers returns the tasks a task waits for: its blockers among tasks
+// that are not closed yet
+func OpenBlockers(tasks []*Item, id string) []string {
+	byID := make(map[string]*Item)
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	return TaskDeps(tasks).Waiting(id, func(b string) (bool, bool) {
+		t, ok := byID[b]
+		return ok && t.IsClosed(), ok
+	})
+}
+
+// Dependents returns the IDs of the tasks id blocks, in order
+func (d Deps) Dependents(id string) []string {
+	var dependents []string
+	for task, blockers := range d {
+		for _, b := range blockers {
+			if b == id {
+				dependents = append(dependents, task)
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// Cycle returns a dependency cycle as the IDs along it, starting and
+// ending with the same task, or nil if there is none
+func (d Deps) Cycle() []string {
+	ids := make([]string, 0, len(d))
+	for id := range d {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visiting:
+			for n, p := range path {
+				if p == id {
+					return append(append([]string(nil), path[n:]...), id)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		blockers := append([]string(nil), d[id]...)
+		sort.Strings(blockers)
+		for _, b := range blockers {
+			if cycle := visit(b); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, id := range ids {
+		if cycle := visit(id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package items
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCycle(t *testing.T) {
+	tests := []struct {
+		name string
+		deps Deps
+		want []string
+	}{
+		{"none", Deps{}, nil},
+		{"chain", Deps{"task-003": {"task-002"}, "task-002": {"task-001"}}, nil},
+		{"diamond", Deps{"task-004": {"task-002", "task-003"}, "task-002": {"task-001"}, "task-003": {"task-001"}}, nil},
+		{"self", Deps{"task-001": {"task-001"}}, []string{"task-001", "task-001"}},
+		{"two", Deps{"task-001": {"task-002"}, "task-002": {"task-001"}}, []string{"task-001", "task-002", "task-001"}},
+		{
+			name: "behind a chain",
+			deps: Deps{"task-001": {"task-002"}, "task-002": {"task-003"}, "task-003": {"task-004"}, "task-004": {"task-002"}},
+			want: []string{"task-002", "task-003", "task-004", "task-002"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.deps.Cycle(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenBlockers(t *testing.T) {
+	task := func(id, status string, links ...Link) *Item {
+		return &Item{ID: id, Type: TypeTask, Status: status, Links: links}
+	}
+	tasks := []*Item{
+		task("task-001", StatusDone),
+		task("task-002", StatusInProgress, Link{Rel: LinkBlocks, Target: "task-004"}),
+		task("task-003", StatusOpen),
+		task("task-004", StatusOpen,
+			Link{Rel: LinkBlockedBy, Target: "task-001"},
+			Link{Rel: LinkBlockedBy, Target: "task-003"},
+			Link{Rel: LinkBlockedBy, Target: "task-009"}),
+		task("task-005", StatusOpen, Link{Rel: LinkBlockedBy, Target: "task-004"}),
+	}
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		// Done and missing blockers don't hold a task up; blocks and
+		// blocked-by links both count
+		{"task-004", []string{"task-002", "task-003"}},
+		{"task-005", []string{"task-004"}},
+		{"task-001", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := OpenBlockers(tasks, tt.id); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OpenBlockers(%s) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	if got, want := TaskDeps(tasks).Dependents("task-004"), []string{"task-005"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents(task-004) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,467 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoFrontmatter is returned by Parse for content that doesn't start with
+// a frontmatter block, such as items in the legacy format
+var ErrNoFrontmatter = errors.New("no frontmatter")
+
+// frontmatterDelim opens and closes the frontmatter block
+const frontmatterDelim = "---"
+
+// dateLayout is used for times at midnight UTC, which only carry a date
+const dateLayout = "2006-01-02"
+
+// Parse parses an item file. The frontmatter is the YAML subset Format
+// writes: scalars, flow lists of scalars and block lists of scalars or
+// one-level maps.
+func Parse(content string) (*Item, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(content, frontmatterDelim+"\n")
+	if !ok {
+		return nil, ErrNoFrontmatter
+	}
+
+	var header []string
+	closed := false
+	for !closed && rest != "" {
+		line, after, _ := strings.Cut(rest, "\n")
+		rest = after
+		if line == frontmatterDelim {
+			closed = true
+			break
+		}
+		header = append(header, line)
+	}
+	if !closed {
+		return nil, errors.New("frontmatter is not closed")
+	}
+
+	item := &Item{Body: rest}
+	if err := item.parseHeader(header); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// yamlEntry is one top-level key of the frontmatter with its scalar value
+// or the elements of its list
+type yamlEntry struct {
+	line   int
+	key    string
+	value  string
+	isList bool
+	// flow marks a list written inline, which takes no indented lines
+	flow bool
+	list []yamlElement
+}
+
+// yamlElement is an element of a block list: a scalar or a map
+type yamlElement struct {
+	scalar string
+	fields []Field
+}
+
+// parseHeader fills the item from the frontmatter lines
+func (i *Item) parseHeader(lines []string) error {
+	entries, err := parseYAML(lines)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := i.setEntry(e); err != nil {
+			return fmt.Errorf("frontmatter line %d: %s: %w", e.line, e.key, err)
+		}
+	}
+	if i.ID == "" {
+		return errors.New("frontmatter has no id")
+	}
+	return nil
+}
+
+// setEntry sets the field named by a frontmatter entry
+func (i *Item) setEntry(e yamlEntry) error {
+	var err error
+	switch e.key {
+	case "labels":
+		i.Labels, err = e.scalars()
+		return err
+	case "sources":
+		for _, el := range e.list {
+			s, err := parseSource(el)
+			if err != nil {
+				return err
+			}
+			i.Sources = append(i.Sources, s)
+		}
+		return nil
+	case "history":
+		for _, el := range e.list {
+			t, err := parseTransition(el)
+			if err != nil {
+				return err
+			}
+			i.History = append(i.History, t)
+		}
+		return nil
+	case "links":
+		for _, el := range e.list {
+			l := Link{Rel: el.get("rel"), Target: el.get("target")}
+			if l.Rel == "" || l.Target == "" {
+				return errors.New("links need a rel and a target")
+			}
+			i.Links = append(i.Links, l)
+		}
+		return nil
+	}
+
+	if e.isList {
+		return errors.New("expected a single value")
+	}
+	switch e.key {
+	case "id":
+		i.ID = e.value
+	case "type":
+		i.Type = Type(e.value)
+	case "status":
+		i.Status = e.value
+	case "priority":
+		i.Priority = e.value
+	case "created":
+		i.Created, err = parseTime(e.value)
+	case "updated":
+		i.Updated, err = parseTime(e.value)
+	default:
+		i.Extra = append(i.Extra, Field{Key: e.key, Value: e.value})
+	}
+	return err
+}
+
+// scalars returns the elements of a list entry, which must all be scalars.
+// An empty scalar value is an empty list.
+func (e yamlEntry) scalars() ([]string, error) {
+	if !e.isList {
+		if e.value == "" {
+			return nil, nil
+		}
+		return nil, errors.New("expected a list")
+	}
+	values := make([]string, 0, len(e.list))
+	for _, el := range e.list {
+		if el.fields != nil {
+			return nil, errors.New("expected a list of values")
+		}
+		values = append(values, el.scalar)
+	}
+	return values, nil
+}
+
+// get returns the value of a key of a map element
+func (el yamlElement) get(key string) string {
+	for _, f := range el.fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// parseSource reads a source from a map element
+func parseSource(el yamlElement) (Source, error) {
+	s := Source{Path: el.get("path"), Lines: el.get("lines"), Commit: el.get("commit")}
+	if feed := el.get("feed"); feed != "" {
+		id, err := strconv.Atoi(feed)
+		if err != nil || id < 1 {
+			return s, fmt.Errorf("invalid feed %q", feed)
+		}
+		s.Feed = id
+	}
+	if s.Feed == 0 && s.Path == "" {
+		return s, errors.New("sources need a feed or a path")
+	}
+	return s, nil
+}
+
+// parseTransition reads a history entry from a map element
+func parseTransition(el yamlElement) (Transition, error) {
+	t := Transition{From: el.get("from"), To: el.get("to"), Actor: el.get("actor"), Reason: el.get("reason")}
+	if t.To == "" {
+		return t, errors.New("history entries need a to status")
+	}
+	var err error
+	t.Time, err = parseTime(el.get("at"))
+	return t, err
+}
+
+// parseTime accepts a date or an RFC 3339 time
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseYAML reads the top-level keys of the frontmatter
+func parseYAML(lines []string) ([]yamlEntry, error) {
+	var entries []yamlEntry
+	for n, line := range lines {
+		lineNo := n + 2 // After the opening delimiter, counting from 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if line[0] != ' ' {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("frontmatter line %d: expected 'key: value'", lineNo)
+			}
+			e := yamlEntry{line: lineNo, key: strings.TrimSpace(key)}
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(value, "[") {
+				list, err := parseFlowList(value)
+				if err != nil {
+					return nil, fmt.Errorf("frontmatter line %d: %w", lineNo, err)
+				}
+				e.isList, e.flow = true, true
+				for _, v := range list {
+					e.list = append(e.list, yamlElement{scalar: v})
+				}
+			} else if e.value, ok = unquote(value); !ok {
+				return nil, fmt.Errorf("frontmatter line %d: invalid quoted value", lineNo)
+			}
+			entries = append(entries, e)
+			continue
+		}
+
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("frontmatter line %d: unexpected indentation", lineNo)
+		}
+		e := &entries[len(entries)-1]
+		if e.value != "" || e.flow {
+			return nil, fmt.Errorf("frontmatter line %d: unexpected indentation", lineNo)
+		}
+		e.isList = true
+
+		if rest, ok := strings.CutPrefix(trimmed, "-"); ok {
+			rest = strings.TrimSpace(rest)
+			if key, value, ok := cutMapEntry(rest); ok {
+				e.list = append(e.list, yamlElement{fields: []Field{{Key: key, Value: value}}})
+				continue
+			}
+			value, ok := unquote(rest)
+			if !ok {
+				return nil, fmt.Errorf("frontmatter line %d: invalid quoted value", lineNo)
+			}
+			e.list = append(e.list, yamlElement{scalar: value})
+			continue
+		}
+
+		// Another key of the map element started by the last "- key: value"
+		key, value, ok := cutMapEntry(trimmed)
+		if !ok || len(e.list) == 0 || e.list[len(e.list)-1].fields == nil {
+			return nil, fmt.Errorf("frontmatter line %d: expected 'key: value'", lineNo)
+		}
+		el := &e.list[len(e.list)-1]
+		el.fields = append(el.fields, Field{Key: key, Value: value})
+	}
+	return entries, nil
+}
+
+// cutMapEntry splits "key: value" where key is a plain identifier. Values
+// that merely contain a colon, like "Feed: #3" in quotes, are not entries.
+func cutMapEntry(s string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(s, ":")
+	if !ok || key == "" || strings.ContainsAny(key, " \"'[{") {
+		return "", "", false
+	}
+	if value != "" && value[0] != ' ' {
+		return "", "", false
+	}
+	value, ok = unquote(strings.TrimSpace(value))
+	return key, value, ok
+}
+
+// parseFlowList parses a flow list of scalars such as "[a, "b c"]"
+func parseFlowList(s string) ([]string, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, errors.New("flow list is not closed")
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return []string{}, nil
+	}
+
+	var values []string
+	for inner != "" {
+		var raw string
+		if inner[0] == '"' || inner[0] == '\'' {
+			end := closingQuote(inner)
+			if end < 0 {
+				return nil, errors.New("invalid quoted value")
+			}
+			raw, inner = inner[:end+1], strings.TrimSpace(inner[end+1:])
+			if inner != "" && !strings.HasPrefix(inner, ",") {
+				return nil, errors.New("expected ',' after quoted value")
+			}
+		} else {
+			raw, inner, _ = strings.Cut(inner, ",")
+			inner = "," + inner
+		}
+		value, ok := unquote(strings.TrimSpace(raw))
+		if !ok {
+			return nil, errors.New("invalid quoted value")
+		}
+		values = append(values, value)
+		inner = strings.TrimSpace(strings.TrimPrefix(inner, ","))
+	}
+	return values, nil
+}
+
+// closingQuote returns the index of the quote closing the quoted scalar s
+// starts with, or -1
+func closingQuote(s string) int {
+	quote := s[0]
+	for n := 1; n < len(s); n++ {
+		switch {
+		case quote == '"' && s[n] == '\\':
+			n++
+		case s[n] == quote && quote == '\'' && n+1 < len(s) && s[n+1] == '\'':
+			n++
+		case s[n] == quote:
+			return n
+		}
+	}
+	return -1
+}
+
+// unquote returns the value of a plain, single-quoted or double-quoted scalar
+func unquote(s string) (string, bool) {
+	if len(s) < 2 {
+		return s, true
+	}
+	switch s[0] {
+	case '"':
+		v, err := strconv.Unquote(s)
+		return v, err == nil
+	case '\'':
+		if s[len(s)-1] != '\'' {
+			return "", false
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), true
+	}
+	return s, true
+}
+
+// Format returns the item file content: the frontmatter followed by the
+// body. Parse(Format(item)) yields the same item.
+func (i *Item) Format() string {
+	var b strings.Builder
+	b.WriteString(frontmatterDelim + "\n")
+	writeScalar(&b, "id", i.ID)
+	writeScalar(&b, "type", string(i.Type))
+	writeScalar(&b, "status", i.Status)
+	writeScalar(&b, "priority", i.Priority)
+	if len(i.Labels) > 0 {
+		quoted := make([]string, len(i.Labels))
+		for n, l := range i.Labels {
+			quoted[n] = quote(l, true)
+		}
+		fmt.Fprintf(&b, "labels: [%s]\n", strings.Join(quoted, ", "))
+	}
+	writeScalar(&b, "created", formatTime(i.Created))
+	writeScalar(&b, "updated", formatTime(i.Updated))
+
+	if len(i.Sources) > 0 {
+		b.WriteString("sources:\n")
+		for _, s := range i.Sources {
+			var fields []Field
+			if s.Feed > 0 {
+				fields = append(fields, Field{"feed", strconv.Itoa(s.Feed)})
+			}
+			for _, f := range []Field{{"path", s.Path}, {"lines", s.Lines}, {"commit", s.Commit}} {
+				if f.Value != "" {
+					fields = append(fields, f)
+				}
+			}
+			writeMapElement(&b, fields)
+		}
+	}
+	if len(i.Links) > 0 {
+		b.WriteString("links:\n")
+		for _, l := range i.Links {
+			writeMapElement(&b, []Field{{"rel", l.Rel}, {"target", l.Target}})
+		}
+	}
+	if len(i.History) > 0 {
+		b.WriteString("history:\n")
+		for _, t := range i.History {
+			var fields []Field
+			for _, f := range []Field{{"from", t.From}, {"to", t.To}, {"actor", t.Actor}, {"at", formatTime(t.Time)}, {"reason", t.Reason}} {
+				if f.Value != "" {
+					fields = append(fields, f)
+				}
+			}
+			writeMapElement(&b, fields)
+		}
+	}
+	for _, f := range i.Extra {
+		writeScalar(&b, f.Key, f.Value)
+	}
+	b.WriteString(frontmatterDelim + "\n")
+	b.WriteString(i.Body)
+	return b.String()
+}
+
+// writeScalar writes a top-level key unless its value is empty
+func writeScalar(b *strings.Builder, key, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s: %s\n", key, quote(value, false))
+	}
+}
+
+// writeMapElement writes a block list element holding a map
+func writeMapElement(b *strings.Builder, fields []Field) {
+	for n, f := range fields {
+		prefix := "    "
+		if n == 0 {
+			prefix = "  - "
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", prefix, f.Key, quote(f.Value, false))
+	}
+}
+
+// formatTime writes times at midnight UTC as a date
+func formatTime(t time.Time) string {
+	switch {
+	case t.IsZero():
+		return ""
+	case t.Equal(t.UTC().Truncate(24 * time.Hour)):
+		return t.UTC().Format(dateLayout)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// quote returns s as a YAML scalar, double-quoted when it would otherwise
+// be read differently. In flow lists commas and brackets need quoting too.
+func quote(s string, inFlow bool) string {
+	needsQuote := s == "" || strings.TrimSpace(s) != s ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.ContainsAny(s, "\n\t") ||
+		strings.Contains(s, ": ") || strings.HasSuffix(s, ":") || strings.Contains(s, " #") ||
+		inFlow && strings.ContainsAny(s, ",[]{}")
+	if needsQuote {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+package items
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	created := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 2, 3, 14, 5, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		item *Item
+	}{
+		{
+			name: "minimal",
+			item: &Item{ID: "issue-001", Type: TypeIssue, Status: StatusOpen, Body: "# Issue: Wrong API style\n"},
+		},
+		{
+			name: "every field",
+			item: &Item{
+				ID:       "task-004",
+				Type:     TypeTask,
+				Status:   StatusInProgress,
+				Priority: "P1",
+				Labels:   []string{"api", "needs, quoting", "[x]"},
+				Created:  created,
+				Updated:  updated,
+				Sources: []Source{
+					{Feed: 3},
+					{Path: "api/server.go", Lines: "10-20", Commit: "abc123"},
+				},
+				Links: []Link{{Rel: LinkBlockedBy, Target: "task-002"}},
+				History: []Transition{
+					{From: StatusOpen, To: StatusInProgress, Actor: "Ada", Time: updated, Reason: "started: see #3"},
+				},
+				Extra: []Field{{Key: "severity", Value: "high"}, {Key: "note", Value: "key: value"}},
+				Body:  "# Task: Fix the parser\n\n## Description\n\n---\nNot a delimiter.\n",
+			},
+		},
+		{
+			name: "values needing quotes",
+			item: &Item{
+				ID:     "question-002",
+				Type:   TypeQuestion,
+				Status: StatusOpen,
+				Extra: []Field{
+					{Key: "origin", Value: " padded "},
+					{Key: "quote", Value: `say "hi"`},
+					{Key: "hash", Value: "#1"},
+					{Key: "multiline", Value: "one\ntwo"},
+				},
+				Body: "# Question: Why?\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.item.Format()
+			got, err := Parse(content)
+			if err != nil {
+				t.Fatalf("Parse(Format()) error: %v\n%s", err, content)
+			}
+			if !reflect.DeepEqual(got, tt.item) {
+				t.Errorf("Parse(Format()) = %+v, want %+v\n%s", got, tt.item, content)
+			}
+			if again := got.Format(); again != content {
+				t.Errorf("Format is not stable:\n%s\nthen\n%s", content, again)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := `---
+id: issue-007
+type: issue
+status: open
+# A comment
+labels:
+  - api
+  - 'it''s'
+created: 2025-01-31T09:30:00Z
+sources:
+  - feed: 2
+  - path: "main.go"
+    lines: 5
+---
+# Issue: Title
+`
+	item, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != "issue-007" || item.Type != TypeIssue || item.Status != StatusOpen {
+		t.Errorf("Parse() = %+v, want issue-007, an open issue", item)
+	}
+	if want := []string{"api", "it's"}; !reflect.DeepEqual(item.Labels, want) {
+		t.Errorf("Labels = %q, want %q", item.Labels, want)
+	}
+	if want := time.Date(2025, 1, 31, 9, 30, 0, 0, time.UTC); !item.Created.Equal(want) {
+		t.Errorf("Created = %v, want %v", item.Created, want)
+	}
+	if want := []Source{{Feed: 2}, {Path: "main.go", Lines: "5"}}; !reflect.DeepEqual(item.Sources, want) {
+		t.Errorf("Sources = %+v, want %+v", item.Sources, want)
+	}
+	if item.Title() != "Title" {
+		t.Errorf("Title() = %q, want %q", item.Title(), "Title")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no frontmatter", "# Issue: Title\n", "no frontmatter"},
+		{"not closed", "---\nid: issue-001\n", "not closed"},
+		{"no id", "---\nstatus: open\n---\n", "no id"},
+		{"no colon", "---\nid issue-001\n---\n", "line 2"},
+		{"stray indentation", "---\n  - a\n---\n", "unexpected indentation"},
+		{"list for scalar", "---\nid: [a, b]\n---\n", "expected a single value"},
+		{"unclosed flow list", "---\nid: issue-001\nlabels: [a, b\n---\n", "not closed"},
+		{"bad feed", "---\nid: issue-001\nsources:\n  - feed: x\n---\n", "invalid feed"},
+		{"history without to", "---\nid: issue-001\nhistory:\n  - from: open\n---\n", "need a to status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse("# Issue: Title\n"); !errors.Is(err, ErrNoFrontmatter) {
+		t.Errorf("Parse(legacy) error = %v, want ErrNoFrontmatter", err)
+	}
+}
@@ -0,0 +1,297 @@
+// Package items models the work items kept in META: Issues, Questions,
+// Proposals and Tasks. Every item is a Markdown file in the directory of
+// its type. A YAML frontmatter block holds the fields lm reads; the body
+// after it is free-form Markdown that starts with the item's title.
+package items
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the kind of a work item
+type Type string
+
+// Item types
+const (
+	TypeIssue    Type = "issue"
+	TypeQuestion Type = "question"
+	TypeProposal Type = "proposal"
+	TypeTask     Type = "task"
+)
+
+// Types lists every item type
+var Types = []Type{TypeIssue, TypeQuestion, TypeProposal, TypeTask}
+
+// typeDirs maps the META directories to the type of the items in them
+var typeDirs = map[string]Type{
+	"Issues":    TypeIssue,
+	"Questions": TypeQuestion,
+	"Proposals": TypeProposal,
+	"Tasks":     TypeTask,
+}
+
+// Dir returns the META directory new items of the type are filed in
+func (t Type) Dir() string {
+	switch t {
+	case TypeIssue:
+		return "Issues"
+	case TypeQuestion:
+		return "Questions"
+	case TypeProposal:
+		return "Proposals"
+	case TypeTask:
+		return "Tasks"
+	}
+	return ""
+}
+
+// ParseType returns the type named by s, accepting the singular, the
+// plural and the directory name in any case
+func ParseType(s string) (Type, error) {
+	name := strings.ToLower(s)
+	for _, t := range Types {
+		if name == string(t) || name == string(t)+"s" || name == strings.ToLower(t.Dir()) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown item type %q", s)
+}
+
+// TypeOfPath returns the type of the item file at a META path relative to
+// the branch directory, and false if the path is not an item file
+func TypeOfPath(p string) (Type, bool) {
+	dir, name, ok := strings.Cut(p, "/")
+	if !ok || strings.Contains(name, "/") || path.Ext(name) != ".md" {
+		return "", false
+	}
+	t, ok := typeDirs[dir]
+	return t, ok
+}
+
+// Source is a citation backing an item: a user feed or a location in the
+// code
+type Source struct {
+	// Feed is the ID of the cited feed, 0 if the source is code
+	Feed int
+	// Path is the cited file or directory
+	Path string
+	// Lines is the cited line or range, e.g. "42" or "10-20"
+	Lines string
+	// Commit is the code commit the citation refers to, if pinned
+	Commit string
+}
+
+// String returns the source in the citation syntax of META,
+// e.g. "Feed #3" or "Source: api/server.go:10-20"
+func (s Source) String() string {
+	if s.Feed > 0 {
+		return "Feed #" + strconv.Itoa(s.Feed)
+	}
+	ref := s.Path
+	if s.Lines != "" {
+		ref += ":" + s.Lines
+	}
+	if s.Commit != "" {
+		ref += "@" + s.Commit
+	}
+	return "Source: " + ref
+}
+
+// Link relates an item to another item
+type Link struct {
+	// Rel is the relation, e.g. "task" on an issue that became a task
+	Rel string
+	// Target is the ID of the other item
+	Target string
+}
+
+// Field is a frontmatter field lm doesn't interpret, such as an issue's
+// severity. Fields keep the order they were read in.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Item is a work item. Path is where the item is stored and is not part of
+// its content.
+type Item struct {
+	Path string
+
+	ID       string
+	Type     Type
+	Status   string
+	Priority string
+	Labels   []string
+	Created  time.Time
+	Updated  time.Time
+	Sources  []Source
+	Links    []Link
+	History  []Transition
+	Extra    []Field
+
+	// Body is the Markdown after the frontmatter, starting with the title
+	Body string
+}
+
+// New returns an open item whose body holds only its title heading
+func New(typ Type, id, title string, now time.Time) *Item {
+	return &Item{
+		ID:      id,
+		Type:    typ,
+		Status:  StatusOpen,
+		Created: now,
+		Updated: now,
+		Body:    "# " + title + "\n",
+	}
+}
+
+// typePrefix matches the type prefix of item headings, as in "# Issue: Title"
+var typePrefix = regexp.MustCompile(`^(?i:issue|question|proposal|suggestion|task): *`)
+
+// Title returns the text of the first heading of the body without a type
+// prefix such as "Issue: "
+func (i *Item) Title() string {
+	for _, line := range strings.Split(i.Body, "\n") {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return typePrefix.ReplaceAllString(strings.TrimSpace(title), "")
+		}
+	}
+	return ""
+}
+
+// IsOpen reports whether the item still waits for a decision or work
+func (i *Item) IsOpen() bool {
+	return i.Status == StatusOpen
+}
+
+// Section returns the text under the body's "## name" heading up to the
+// next heading of the same or a higher level, or "" if there is none.
+// The name is matched case-insensitively.
+func (i *Item) Section(name string) string {
+	var lines []string
+	in := false
+	for _, line := range strings.Split(i.Body, "\n") {
+		heading, isHeading := strings.CutPrefix(line, "## ")
+		if isHeading || strings.HasPrefix(line, "# ") {
+			if in {
+				break
+			}
+			in = isHeading && strings.EqualFold(strings.TrimSpace(heading), name)
+			continue
+		}
+		if in {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Get returns the value of an extra field, or "" if the item has none
+func (i *Item) Get(key string) string {
+	for _, f := range i.Extra {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Set sets an extra field, appending it if the item has none
+func (i *Item) Set(key, value string) {
+	for n := range i.Extra {
+		if i.Extra[n].Key == key {
+			i.Extra[n].Value = value
+			return
+		}
+	}
+	i.Extra = append(i.Extra, Field{Key: key, Value: value})
+}
+
+// LinkTo adds a link unless the item already has it
+func (i *Item) LinkTo(rel, target string) {
+	for _, l := range i.Links {
+		if l.Rel == rel && l.Target == target {
+			return
+		}
+	}
+	i.Links = append(i.Links, Link{Rel: rel, Target: target})
+}
+
+// Unlink removes a link
+func (i *Item) Unlink(rel, target string) {
+	kept := i.Links[:0]
+	for _, l := range i.Links {
+		if l.Rel != rel || l.Target != target {
+			kept = append(kept, l)
+		}
+	}
+	i.Links = kept
+}
+
+// Linked returns the targets of the item's links with relation rel
+func (i *Item) Linked(rel string) []string {
+	var targets []string
+	for _, l := range i.Links {
+		if l.Rel == rel {
+			targets = append(targets, l.Target)
+		}
+	}
+	return targets
+}
+
+// FileName returns the file name for a new item: its ID followed by a slug
+// of the title, as in "issue-001-wrong-api.md"
+func FileName(id, title string) string {
+	if slug := Slug(title); slug != "" {
+		return id + "-" + slug + ".md"
+	}
+	return id + ".md"
+}
+
+// slugPattern matches the runs of characters a slug replaces with a dash
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug returns a short lowercase, dash-separated form of s
+func Slug(s string) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	for len(slug) > 40 {
+		cut := strings.LastIndex(slug[:40], "-")
+		if cut <= 0 {
+			slug = slug[:40]
+			break
+		}
+		slug = slug[:cut]
+	}
+	return slug
+}
+
+// idPattern matches the ID at the start of an item file name
+var idPattern = regexp.MustCompile(`^[a-z]+-\d+`)
+
+// FormatID returns the ID of the nth item of a type, as in "task-004"
+func FormatID(typ Type, n int) string {
+	return fmt.Sprintf("%s-%03d", typ, n)
+}
+
+// IDNumber returns the number in an item ID, whatever its prefix: 4 for
+// "task-004" and 2 for "suggest-002". It returns 0 for an ID without one.
+func IDNumber(id string) int {
+	_, digits, _ := strings.Cut(idPattern.FindString(id), "-")
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+// IDFromPath derives an item ID from its file name, for items that don't
+// record one: "Issues/issue-001-wrong-api.md" has the ID "issue-001"
+func IDFromPath(p string) string {
+	name := strings.TrimSuffix(path.Base(p), ".md")
+	if id := idPattern.FindString(name); id != "" {
+		return id
+	}
+	return name
+}
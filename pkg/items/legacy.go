@@ -0,0 +1,133 @@
+package items
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Older skills wrote item fields as bold Markdown lines below the title:
+//
+//	# Issue: Wrong API style
+//
+//	**ID**: issue-001
+//	**Status**: Open
+//	**Detected**: 2025-01-31
+var (
+	legacyFieldPattern  = regexp.MustCompile(`^\*\*([^*]+)\*\*:\s*(.*)$`)
+	feedCitationPattern = regexp.MustCompile(`\[(?:Feed|Serves: Feed) #(\d+)\]`)
+	codeCitationPattern = regexp.MustCompile(`\[(?:Source|Current|Improves): ([^\]\s]+)\]`)
+	blankLinesPattern   = regexp.MustCompile(`\n{3,}`)
+)
+
+// legacyDateFields are the bold fields older skills recorded the creation
+// date in
+var legacyDateFields = map[string]bool{
+	"created":  true,
+	"detected": true,
+	"proposed": true,
+	"date":     true,
+}
+
+// ParseLegacy converts an item in the bold-field format to an Item. The
+// fields in the block below the title move to the frontmatter; fields
+// lm doesn't interpret are kept as extra fields. Feed and code citations
+// in the body become sources. p is the item's path, which tells its type
+// and, for items without an ID field, its ID.
+func ParseLegacy(p, content string) *Item {
+	typ, _ := TypeOfPath(p)
+	item := &Item{Path: p, Type: typ}
+
+	var body []string
+	inFields := true
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "## ") {
+			inFields = false
+		}
+		if m := legacyFieldPattern.FindStringSubmatch(line); inFields && m != nil {
+			item.setLegacyField(m[1], strings.TrimSpace(m[2]))
+			continue
+		}
+		body = append(body, line)
+	}
+	item.Body = blankLinesPattern.ReplaceAllString(strings.Join(body, "\n"), "\n\n")
+
+	if item.ID == "" {
+		item.ID = IDFromPath(p)
+	}
+	if item.Status == "" {
+		item.Status = StatusOpen
+	}
+	item.addCitations(item.Body)
+	return item
+}
+
+// setLegacyField sets the item field a bold field maps to
+func (i *Item) setLegacyField(name, value string) {
+	key := legacyKey(name)
+	switch {
+	case value == "":
+	case key == "id":
+		i.ID = value
+	case key == "status":
+		i.Status = strings.ToLower(value)
+		if i.Status == "solved" {
+			// The clarifier marked answered questions solved
+			i.Status = StatusAnswered
+		}
+	case key == "priority":
+		i.Priority = strings.ToUpper(value)
+	case key == "labels":
+		for _, label := range strings.Split(value, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				i.Labels = append(i.Labels, label)
+			}
+		}
+	case legacyDateFields[key] && i.Created.IsZero():
+		created, err := time.Parse(dateLayout, value)
+		if err != nil {
+			i.Set(key, value)
+			return
+		}
+		i.Created = created
+	case key == "source" && feedCitationPattern.MatchString("["+value+"]"):
+		i.addCitations("[" + value + "]")
+	case key == "source":
+		// Where a question came from, e.g. "Sync" or "Audit"
+		i.Set("origin", value)
+	case key == "type":
+		// Questions recorded their kind as Type
+		i.Set("category", value)
+	default:
+		i.Set(key, value)
+	}
+}
+
+// legacyKey turns a bold field name into a frontmatter key
+func legacyKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// addCitations adds the feed and code citations in text as sources
+func (i *Item) addCitations(text string) {
+	for _, m := range feedCitationPattern.FindAllStringSubmatch(text, -1) {
+		if feed, err := strconv.Atoi(m[1]); err == nil {
+			i.addSource(Source{Feed: feed})
+		}
+	}
+	for _, m := range codeCitationPattern.FindAllStringSubmatch(text, -1) {
+		path, lines, _ := strings.Cut(m[1], ":")
+		i.addSource(Source{Path: path, Lines: lines})
+	}
+}
+
+// addSource adds a source unless the item already cites it
+func (i *Item) addSource(s Source) {
+	for _, existing := range i.Sources {
+		if existing == s {
+			return
+		}
+	}
+	i.Sources = append(i.Sources, s)
+}
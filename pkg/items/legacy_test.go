@@ -0,0 +1,114 @@
+package items
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    *Item
+	}{
+		{
+			name: "issue",
+			path: "Issues/issue-001-wrong-api.md",
+			content: `# Issue: Wrong API style
+
+**ID**: issue-001
+**Status**: Open
+**Priority**: p1
+**Labels**: api, style
+**Detected**: 2025-01-31
+**Severity**: High
+
+
+## Description
+
+The API doesn't follow [Feed #3]. See [Source: api/server.go:10-20].
+
+**Note**: bold lines in sections stay in the body
+`,
+			want: &Item{
+				Path:     "Issues/issue-001-wrong-api.md",
+				ID:       "issue-001",
+				Type:     TypeIssue,
+				Status:   StatusOpen,
+				Priority: "P1",
+				Labels:   []string{"api", "style"},
+				Created:  time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+				Sources:  []Source{{Feed: 3}, {Path: "api/server.go", Lines: "10-20"}},
+				Extra:    []Field{{Key: "severity", Value: "High"}},
+				Body: `# Issue: Wrong API style
+
+## Description
+
+The API doesn't follow [Feed #3]. See [Source: api/server.go:10-20].
+
+**Note**: bold lines in sections stay in the body
+`,
+			},
+		},
+		{
+			name: "question without ID",
+			path: "Questions/question-004.md",
+			content: `# Question: Which database?
+
+**Status**: Solved
+**Source**: Sync
+**Type**: Ambiguity
+**Date**: not a date
+`,
+			want: &Item{
+				Path:    "Questions/question-004.md",
+				ID:      "question-004",
+				Type:    TypeQuestion,
+				Status:  StatusAnswered,
+				Extra:   []Field{{Key: "origin", Value: "Sync"}, {Key: "category", Value: "Ambiguity"}, {Key: "date", Value: "not a date"}},
+				Body:    "# Question: Which database?\n\n",
+				Sources: nil,
+			},
+		},
+		{
+			name:    "moved suggestion citing a feed",
+			path:    "Proposals/suggest-002-cache.md",
+			content: "# Suggestion: Add a cache\n\n**Source**: Feed #5\n",
+			want: &Item{
+				Path:    "Proposals/suggest-002-cache.md",
+				ID:      "suggest-002",
+				Type:    TypeProposal,
+				Status:  StatusOpen,
+				Sources: []Source{{Feed: 5}},
+				Body:    "# Suggestion: Add a cache\n\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseLegacy(tt.path, tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLegacy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFileMigratesLegacy(t *testing.T) {
+	legacy, err := ParseFile("Issues/issue-002.md", "# Issue: Slow\n\n**Status**: Resolved\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := ParseFile(legacy.Path, legacy.Format())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(migrated, legacy) {
+		t.Errorf("migrated item = %+v, want %+v", migrated, legacy)
+	}
+	if migrated.Status != StatusResolved || migrated.Title() != "Slow" {
+		t.Errorf("migrated item has status %q and title %q, want resolved and Slow", migrated.Status, migrated.Title())
+	}
+}
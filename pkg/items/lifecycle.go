@@ -0,0 +1,163 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Item statuses. Every type starts open and goes through its own subset,
+// see lifecycles.
+const (
+	StatusOpen        = "open"
+	StatusResolved    = "resolved"
+	StatusApproved    = "approved"
+	StatusRejected    = "rejected"
+	StatusConfirmed   = "confirmed"
+	StatusTaskCreated = "task-created"
+	StatusWontFix     = "wont-fix"
+	StatusAnswered    = "answered"
+	StatusIssued      = "issued"
+	StatusDismissed   = "dismissed"
+	StatusDeferred    = "deferred"
+	StatusImplemented = "implemented"
+	StatusInProgress  = "in-progress"
+	StatusInReview    = "in-review"
+	StatusBlocked     = "blocked"
+	StatusDone        = "done"
+	StatusCancelled   = "cancelled"
+)
+
+// lifecycle lists the statuses an item type can move to from each status.
+// The first status is where new items start; statuses without successors
+// other than a reopen are final.
+type lifecycle struct {
+	order       []string
+	transitions map[string][]string
+	final       map[string]bool
+}
+
+var lifecycles = map[Type]*lifecycle{
+	// An issue is confirmed in triage, gets a task and is resolved by it
+	TypeIssue: {
+		order: []string{StatusOpen, StatusConfirmed, StatusTaskCreated, StatusResolved, StatusRejected, StatusWontFix},
+		transitions: map[string][]string{
+			StatusOpen:        {StatusConfirmed, StatusTaskCreated, StatusResolved, StatusRejected, StatusWontFix},
+			StatusConfirmed:   {StatusTaskCreated, StatusResolved, StatusRejected, StatusWontFix},
+			StatusTaskCreated: {StatusResolved, StatusConfirmed, StatusWontFix},
+			StatusResolved:    {StatusOpen},
+			StatusRejected:    {StatusOpen},
+			StatusWontFix:     {StatusOpen},
+		},
+		final: map[string]bool{StatusResolved: true, StatusRejected: true, StatusWontFix: true},
+	},
+	// A question is answered, turned into an issue when the answer can't be
+	// found, or dismissed
+	TypeQuestion: {
+		order: []string{StatusOpen, StatusAnswered, StatusIssued, StatusDismissed},
+		transitions: map[string][]string{
+			StatusOpen:      {StatusAnswered, StatusIssued, StatusDismissed},
+			StatusAnswered:  {StatusOpen},
+			StatusIssued:    {StatusOpen},
+			StatusDismissed: {StatusOpen},
+		},
+		final: map[string]bool{StatusAnswered: true, StatusIssued: true, StatusDismissed: true},
+	},
+	// A proposal is approved or rejected in triage, gets a task and is
+	// implemented by it. Deferred proposals come back later.
+	TypeProposal: {
+		order: []string{StatusOpen, StatusApproved, StatusTaskCreated, StatusImplemented, StatusDeferred, StatusRejected},
+		transitions: map[string][]string{
+			StatusOpen:        {StatusApproved, StatusTaskCreated, StatusDeferred, StatusRejected},
+			StatusApproved:    {StatusTaskCreated, StatusImplemented, StatusDeferred, StatusRejected},
+			StatusTaskCreated: {StatusImplemented, StatusApproved, StatusRejected},
+			StatusDeferred:    {StatusOpen, StatusRejected},
+			StatusImplemented: {StatusOpen},
+			StatusRejected:    {StatusOpen},
+		},
+		final: map[string]bool{StatusImplemented: true, StatusRejected: true},
+	},
+	// A task is worked on, reviewed and done
+	TypeTask: {
+		order: []string{StatusOpen, StatusInProgress, StatusBlocked, StatusInReview, StatusDone, StatusCancelled},
+		transitions: map[string][]string{
+			StatusOpen:       {StatusInProgress, StatusBlocked, StatusCancelled},
+			StatusInProgress: {StatusInReview, StatusBlocked, StatusOpen, StatusDone, StatusCancelled},
+			StatusBlocked:    {StatusOpen, StatusInProgress, StatusCancelled},
+			StatusInReview:   {StatusDone, StatusInProgress, StatusCancelled},
+			StatusDone:       {StatusOpen},
+			StatusCancelled:  {StatusOpen},
+		},
+		final: map[string]bool{StatusDone: true, StatusCancelled: true},
+	},
+}
+
+// ErrInvalidTransition is returned for a status change the item's lifecycle
+// doesn't allow
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Statuses returns the statuses of a type's lifecycle
+func Statuses(typ Type) []string {
+	if lc := lifecycles[typ]; lc != nil {
+		return lc.order
+	}
+	return nil
+}
+
+// ValidStatus reports whether status is part of the type's lifecycle
+func ValidStatus(typ Type, status string) bool {
+	lc := lifecycles[typ]
+	return lc != nil && lc.transitions[status] != nil
+}
+
+// IsClosed reports whether the item is in a final state of its lifecycle
+func (i *Item) IsClosed() bool {
+	lc := lifecycles[i.Type]
+	return lc != nil && lc.final[i.Status]
+}
+
+// NextStatuses returns the statuses the item can move to. An item whose
+// status is not part of its lifecycle, such as one written by an older
+// skill, may move to any status.
+func (i *Item) NextStatuses() []string {
+	lc := lifecycles[i.Type]
+	if lc == nil {
+		return nil
+	}
+	if next, ok := lc.transitions[i.Status]; ok {
+		return next
+	}
+	return lc.order
+}
+
+// Transition is a status change recorded in an item's history
+type Transition struct {
+	From   string
+	To     string
+	Actor  string
+	Time   time.Time
+	Reason string
+}
+
+// SetStatus moves the item to a new status, recording the change in its
+// history
+func (i *Item) SetStatus(to, actor, reason string, now time.Time) error {
+	if !ValidStatus(i.Type, to) {
+		return fmt.Errorf("%w: %s is not a status of %ss (statuses: %s)",
+			ErrInvalidTransition, to, i.Type, strings.Join(Statuses(i.Type), ", "))
+	}
+	allowed := false
+	for _, next := range i.NextStatuses() {
+		allowed = allowed || next == to
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s %s can't move from %s to %s (allowed: %s)",
+			ErrInvalidTransition, i.Type, i.ID, i.Status, to, strings.Join(i.NextStatuses(), ", "))
+	}
+
+	i.History = append(i.History, Transition{From: i.Status, To: to, Actor: actor, Time: now, Reason: reason})
+	i.Status = to
+	i.Updated = now
+	return nil
+}
@@ -0,0 +1,85 @@
+package items
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetStatus(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		typ     Type
+		from    string
+		to      string
+		allowed bool
+	}{
+		{TypeIssue, StatusOpen, StatusConfirmed, true},
+		{TypeIssue, StatusConfirmed, StatusTaskCreated, true},
+		{TypeIssue, StatusTaskCreated, StatusResolved, true},
+		{TypeIssue, StatusResolved, StatusOpen, true},
+		{TypeIssue, StatusResolved, StatusConfirmed, false},
+		{TypeIssue, StatusOpen, StatusDone, false},
+		{TypeQuestion, StatusOpen, StatusAnswered, true},
+		{TypeQuestion, StatusAnswered, StatusDismissed, false},
+		{TypeProposal, StatusOpen, StatusDeferred, true},
+		{TypeProposal, StatusDeferred, StatusApproved, false},
+		{TypeProposal, StatusApproved, StatusImplemented, true},
+		{TypeTask, StatusOpen, StatusInProgress, true},
+		{TypeTask, StatusOpen, StatusDone, false},
+		{TypeTask, StatusInProgress, StatusInReview, true},
+		{TypeTask, StatusInReview, StatusDone, true},
+		{TypeTask, StatusDone, StatusInProgress, false},
+		// Statuses written by older skills may move anywhere in the lifecycle
+		{TypeIssue, "pending", StatusResolved, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.typ)+" "+tt.from+" to "+tt.to, func(t *testing.T) {
+			item := &Item{ID: FormatID(tt.typ, 1), Type: tt.typ, Status: tt.from}
+			err := item.SetStatus(tt.to, "Ada", "because", now)
+			if !tt.allowed {
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Fatalf("SetStatus() error = %v, want ErrInvalidTransition", err)
+				}
+				if item.Status != tt.from || len(item.History) != 0 {
+					t.Errorf("a refused change left status %q and history %+v", item.Status, item.History)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetStatus() error = %v", err)
+			}
+			want := []Transition{{From: tt.from, To: tt.to, Actor: "Ada", Time: now, Reason: "because"}}
+			if item.Status != tt.to || !item.Updated.Equal(now) || !reflect.DeepEqual(item.History, want) {
+				t.Errorf("after SetStatus: status %q, updated %v, history %+v", item.Status, item.Updated, item.History)
+			}
+		})
+	}
+}
+
+func TestLifecycles(t *testing.T) {
+	for typ, lc := range lifecycles {
+		if lc.order[0] != StatusOpen {
+			t.Errorf("%s items start %s, want open", typ, lc.order[0])
+		}
+		for _, status := range lc.order {
+			next, ok := lc.transitions[status]
+			if !ok {
+				t.Errorf("%s status %s has no transitions", typ, status)
+			}
+			for _, to := range next {
+				if !ValidStatus(typ, to) {
+					t.Errorf("%s status %s moves to %s, which isn't a status of the type", typ, status, to)
+				}
+			}
+			item := &Item{Type: typ, Status: status}
+			if item.IsClosed() != lc.final[status] {
+				t.Errorf("%s %s: IsClosed() = %v, want %v", typ, status, item.IsClosed(), lc.final[status])
+			}
+		}
+	}
+	if ValidStatus(TypeTask, StatusApproved) {
+		t.Error("ValidStatus(task, approved) = true, want false")
+	}
+}
@@ -0,0 +1,75 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Priorities, from the most to the least urgent
+const (
+	PriorityP0 = "P0"
+	PriorityP1 = "P1"
+	PriorityP2 = "P2"
+	PriorityP3 = "P3"
+)
+
+// Priorities lists the priorities from the most to the least urgent
+var Priorities = []string{PriorityP0, PriorityP1, PriorityP2, PriorityP3}
+
+// ErrInvalidPriority is returned for a priority other than P0 to P3
+var ErrInvalidPriority = errors.New("invalid priority")
+
+// ParsePriority returns the priority named by s, accepting "P1", "p1" and
+// "1"
+func ParsePriority(s string) (string, error) {
+	p := strings.ToUpper(strings.TrimSpace(s))
+	if !strings.HasPrefix(p, "P") {
+		p = "P" + p
+	}
+	for _, known := range Priorities {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (priorities: %s)", ErrInvalidPriority, s, strings.Join(Priorities, ", "))
+}
+
+// PriorityRank orders priorities for sorting: 0 for P0 up to 3 for P3, and
+// 4 for items without a priority, which sort last
+func PriorityRank(p string) int {
+	for n, known := range Priorities {
+		if strings.EqualFold(p, known) {
+			return n
+		}
+	}
+	return len(Priorities)
+}
+
+// HasLabel reports whether the item carries a label, ignoring case
+func (i *Item) HasLabel(label string) bool {
+	for _, l := range i.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddLabel adds a label unless the item already carries it
+func (i *Item) AddLabel(label string) {
+	if !i.HasLabel(label) {
+		i.Labels = append(i.Labels, label)
+	}
+}
+
+// RemoveLabel removes a label, ignoring case
+func (i *Item) RemoveLabel(label string) {
+	kept := i.Labels[:0]
+	for _, l := range i.Labels {
+		if !strings.EqualFold(l, label) {
+			kept = append(kept, l)
+		}
+	}
+	i.Labels = kept
+}
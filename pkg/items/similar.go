@@ -0,0 +1,242 @@
+package items
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DuplicateThreshold is the similarity from which two items are reported
+// as likely duplicates
+const DuplicateThreshold = 0.5
+
+// stopWords are English words too common to tell items apart
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "can": true, "for": true, "from": true,
+	"has": true, "have": true, "if": true, "in": true, "into": true, "is": true,
+	"it": true, "its": true, "no": true, "not": true, "of": true, "on": true,
+	"or": true, "should": true, "so": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "this": true,
+	"to": true, "was": true, "were": true, "when": true, "which": true,
+	"will": true, "with": true,
+}
+
+// Match is an item similar to another one
+type Match struct {
+	Item *Item
+	// Score is the cosine similarity of the items' texts, from 0 to 1
+	Score float64
+}
+
+// Corpus compares items by the TF-IDF cosine similarity of their titles
+// and bodies. Words rare across the corpus weigh more than common ones, so
+// the section headings every item has count for little.
+type Corpus struct {
+	items   []*Item
+	vectors []map[string]float64
+}
+
+// NewCorpus indexes the text of items
+func NewCorpus(all []*Item) *Corpus {
+	counts := make([]map[string]int, len(all))
+	df := make(map[string]int)
+	for n, item := range all {
+		counts[n] = termCounts(item)
+		for term := range counts[n] {
+			df[term]++
+		}
+	}
+
+	c := &Corpus{items: all, vectors: make([]map[string]float64, len(all))}
+	for n, tf := range counts {
+		v := make(map[string]float64, len(tf))
+		var norm float64
+		for term, count := range tf {
+			w := (1 + math.Log(float64(count))) * math.Log(1+float64(len(all))/float64(df[term]))
+			v[term] = w
+			norm += w * w
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for term := range v {
+				v[term] /= norm
+			}
+		}
+		c.vectors[n] = v
+	}
+	return c
+}
+
+// Similar returns the items of the corpus other than item whose similarity
+// to it reaches threshold, most similar first. item must be in the corpus.
+func (c *Corpus) Similar(item *Item, threshold float64) []Match {
+	self := c.index(item)
+	if self < 0 {
+		return nil
+	}
+	var matches []Match
+	for n, other := range c.items {
+		if n == self {
+			continue
+		}
+		if score := cosine(c.vectors[self], c.vectors[n]); score >= threshold {
+			matches = append(matches, Match{Item: other, Score: score})
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+// Duplicates groups the items of the corpus that are likely duplicates of
+// each other: items end up in one group when a chain of pairs reaching
+// threshold connects them. Each group is ordered by ID, and each item's
+// score is its best similarity within the group; groups with the most
+// similar pair come first.
+func (c *Corpus) Duplicates(threshold float64) [][]Match {
+	parent := make([]int, len(c.items))
+	for n := range parent {
+		parent[n] = n
+	}
+	var root func(n int) int
+	root = func(n int) int {
+		if parent[n] != n {
+			parent[n] = root(parent[n])
+		}
+		return parent[n]
+	}
+
+	best := make([]float64, len(c.items))
+	for i := range c.items {
+		for j := i + 1; j < len(c.items); j++ {
+			score := cosine(c.vectors[i], c.vectors[j])
+			if score < threshold {
+				continue
+			}
+			parent[root(i)] = root(j)
+			best[i] = math.Max(best[i], score)
+			best[j] = math.Max(best[j], score)
+		}
+	}
+
+	byRoot := make(map[int][]int)
+	for n := range c.items {
+		if best[n] > 0 {
+			byRoot[root(n)] = append(byRoot[root(n)], n)
+		}
+	}
+	var groups [][]Match
+	for _, members := range byRoot {
+		group := make([]Match, 0, len(members))
+		for _, n := range members {
+			group = append(group, Match{Item: c.items[n], Score: best[n]})
+		}
+		sort.Slice(group, func(i, j int) bool {
+			return IDNumber(group[i].Item.ID) < IDNumber(group[j].Item.ID) ||
+				IDNumber(group[i].Item.ID) == IDNumber(group[j].Item.ID) && group[i].Item.Path < group[j].Item.Path
+		})
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if a, b := topScore(groups[i]), topScore(groups[j]); a != b {
+			return a > b
+		}
+		return groups[i][0].Item.Path < groups[j][0].Item.Path
+	})
+	return groups
+}
+
+// index returns the position of item in the corpus, or -1
+func (c *Corpus) index(item *Item) int {
+	for n, other := range c.items {
+		if other == item || (item.Path != "" && other.Path == item.Path) {
+			return n
+		}
+	}
+	return -1
+}
+
+// termCounts counts the terms of an item's title and body. The title
+// counts twice, as it says most about what the item is.
+func termCounts(item *Item) map[string]int {
+	counts := make(map[string]int)
+	for _, text := range []string{item.Title(), item.Body} {
+		for _, term := range Terms(text) {
+			counts[term]++
+		}
+	}
+	return counts
+}
+
+// Terms splits text into the terms items are compared by: lowercased
+// words without stop words, and pairs of adjacent characters in runs of
+// Han script, which has no spaces between words
+func Terms(text string) []string {
+	var terms []string
+	var word, han []rune
+	flush := func() {
+		if len(word) > 1 {
+			if w := string(word); !stopWords[w] {
+				terms = append(terms, w)
+			}
+		}
+		if len(han) == 1 {
+			terms = append(terms, string(han))
+		}
+		for n := 0; n+1 < len(han); n++ {
+			terms = append(terms, string(han[n:n+2]))
+		}
+		word, han = word[:0], han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if len(word) > 0 {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(han) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// cosine returns the similarity of two unit vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, w := range a {
+		dot += w * b[term]
+	}
+	return math.Min(dot, 1)
+}
+
+// sortMatches orders matches from the most to the least similar
+func sortMatches(matches []Match) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Item.Path < matches[j].Item.Path
+	})
+}
+
+// topScore returns the highest score in a group
+func topScore(group []Match) float64 {
+	var top float64
+	for _, m := range group {
+		top = math.Max(top, m.Score)
+	}
+	return top
+}
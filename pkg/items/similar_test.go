@@ -0,0 +1,87 @@
+package items
+
+import (
+	"reflect"
+	"testing"
+)
+
+// similarItems are issues where issue-001 and issue-003 describe the same
+// problem and issue-004 is unrelated
+func similarItems() []*Item {
+	issue := func(id, body string) *Item {
+		return &Item{ID: id, Type: TypeIssue, Path: "Issues/" + id + ".md", Body: body}
+	}
+	return []*Item{
+		issue("issue-001", "# Issue: Login fails with expired session token\n\n## Description\n\nUsers with an expired session token can't log in and see a blank page.\n"),
+		issue("issue-002", "# Issue: Slow dashboard queries\n\n## Description\n\nThe dashboard runs one database query per widget.\n"),
+		issue("issue-003", "# Issue: Expired session token breaks login\n\n## Description\n\nLogging in with an expired session token shows a blank page.\n"),
+		issue("issue-004", "# Issue: 文档缺少安装步骤\n\n## Description\n\n安装文档没有说明依赖。\n"),
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	all := similarItems()
+	corpus := NewCorpus(all)
+
+	matches := corpus.Similar(all[0], DuplicateThreshold)
+	if len(matches) != 1 || matches[0].Item.ID != "issue-003" {
+		t.Fatalf("Similar(issue-001) = %+v, want issue-003 only", matches)
+	}
+	if s := matches[0].Score; s < DuplicateThreshold || s > 1 {
+		t.Errorf("score = %v, want between the threshold and 1", s)
+	}
+
+	if got := corpus.Similar(all[1], DuplicateThreshold); len(got) != 0 {
+		t.Errorf("Similar(issue-002) = %+v, want no matches", got)
+	}
+	if got := corpus.Similar(all[1], 0); len(got) != len(all)-1 {
+		t.Errorf("Similar(issue-002, 0) returned %d matches, want every other item", len(got))
+	}
+	if got := corpus.Similar(&Item{ID: "issue-009", Path: "Issues/issue-009.md"}, 0); got != nil {
+		t.Errorf("Similar(item outside the corpus) = %+v, want nil", got)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	all := similarItems()
+	// A third report of the login problem joins the group of the first two
+	all = append(all, &Item{ID: "issue-005", Type: TypeIssue, Path: "Issues/issue-005.md",
+		Body: "# Issue: Blank page on login with an expired session token\n\n## Description\n\nAn expired session token makes login fail.\n"})
+	corpus := NewCorpus(all)
+
+	groups := corpus.Duplicates(DuplicateThreshold)
+	if len(groups) != 1 {
+		t.Fatalf("Duplicates() = %d groups, want 1", len(groups))
+	}
+	var ids []string
+	for _, m := range groups[0] {
+		ids = append(ids, m.Item.ID)
+		if m.Score < DuplicateThreshold {
+			t.Errorf("%s has score %v, below the threshold", m.Item.ID, m.Score)
+		}
+	}
+	if want := []string{"issue-001", "issue-003", "issue-005"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("group = %v, want %v", ids, want)
+	}
+
+	if groups := corpus.Duplicates(1.01); len(groups) != 0 {
+		t.Errorf("Duplicates(above 1) = %d groups, want none", len(groups))
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The API is slow, and it's broken", []string{"api", "slow", "broken"}},
+		{"Add HTTP/2 support", []string{"add", "http", "support"}},
+		{"安装文档", []string{"安装", "装文", "文档"}},
+		{"使用Go语言", []string{"使用", "go", "语言"}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Store is the part of a META store items are read from and written to.
+// meta.MetaStore implements it; writes are staged until the caller commits.
+type Store interface {
+	Read(filename string) (string, error)
+	List() ([]string, error)
+	Write(filename, content string) error
+}
+
+// Load reads the item at path p. Items still in the legacy bold-field
+// format are converted on the fly.
+func Load(s Store, p string) (*Item, error) {
+	content, err := s.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFile(p, content)
+}
+
+// ParseFile parses the content of the item file at path p, in either format
+func ParseFile(p, content string) (*Item, error) {
+	item, err := Parse(content)
+	if errors.Is(err, ErrNoFrontmatter) {
+		return ParseLegacy(p, content), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	item.Path = p
+	if item.Type == "" {
+		item.Type, _ = TypeOfPath(p)
+	}
+	return item, nil
+}
+
+// FileErrors lists the item files that couldn't be parsed, one error per
+// file naming it. LoadAll, LoadEvery and Find return it along
+// with the items that did load, so one broken file doesn't hide the rest.
+type FileErrors []error
+
+func (e FileErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the files
+func (e FileErrors) Unwrap() []error {
+	return e
+}
+
+// LoadAll reads every item of a type, ordered by path. Files that don't
+// parse are skipped and reported in a FileErrors.
+func LoadAll(s Store, typ Type) ([]*Item, error) {
+	paths, err := itemPaths(s, func(t Type) bool { return t == typ })
+	if err != nil {
+		return nil, err
+	}
+	return loadPaths(s, paths)
+}
+
+// LoadEvery reads the items of every type, ordered by path. Files that
+// don't parse are skipped and reported in a FileErrors.
+func LoadEvery(s Store) ([]*Item, error) {
+	paths, err := itemPaths(s, func(Type) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	return loadPaths(s, paths)
+}
+
+// Find returns the item with the given ID, or the item at a path relative
+// to the branch directory, with or without the .md extension. It returns
+// nil if there is no such item. Files that don't parse only matter
+// when the item isn't found, as it may be one of them: Find then returns
+// their FileErrors.
+func Find(s Store, ref string) (*Item, error) {
+	ref = strings.TrimSuffix(ref, ".md")
+	all, err := LoadEvery(s)
+	var bad FileErrors
+	if err != nil && !errors.As(err, &bad) {
+		return nil, err
+	}
+	for _, item := range all {
+		if item.ID == ref || strings.TrimSuffix(item.Path, ".md") == ref ||
+			strings.TrimSuffix(path.Base(item.Path), ".md") == ref {
+			return item, nil
+		}
+	}
+	return nil, err
+}
+
+// Save stages the item at its path, filing new items in the directory of
+// their type
+func Save(s Store, item *Item) error {
+	if item.Path == "" {
+		item.Path = path.Join(item.Type.Dir(), FileName(item.ID, item.Title()))
+	}
+	return s.Write(item.Path, item.Format())
+}
+
+// MigrateLegacy rewrites every item in the legacy bold-field format with
+// frontmatter and returns the migrated items. The writes are staged; the
+// caller commits them.
+func MigrateLegacy(s Store) ([]*Item, error) {
+	paths, err := itemPaths(s, func(Type) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []*Item
+	for _, p := range paths {
+		content, err := s.Read(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := Parse(content); !errors.Is(err, ErrNoFrontmatter) {
+			continue
+		}
+		item := ParseLegacy(p, content)
+		if err := Save(s, item); err != nil {
+			return nil, err
+		}
+		migrated = append(migrated, item)
+	}
+	return migrated, nil
+}
+
+// itemPaths returns the sorted paths of the item files whose type matches
+func itemPaths(s Store, match func(Type) bool) ([]string, error) {
+	files, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range files {
+		if typ, ok := TypeOfPath(f); ok && match(typ) {
+			paths = append(paths, f)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// loadPaths loads the items at paths, collecting the files that don't
+// parse in a FileErrors
+func loadPaths(s Store, paths []string) ([]*Item, error) {
+	items := make([]*Item, 0, len(paths))
+	var bad FileErrors
+	for _, p := range paths {
+		content, err := s.Read(p)
+		if err != nil {
+			return nil, err
+		}
+		item, err := ParseFile(p, content)
+		if err != nil {
+			bad = append(bad, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	if len(bad) > 0 {
+		return items, bad
+	}
+	return items, nil
+}
@@ -0,0 +1,57 @@
+package items
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// mapStore holds item files in memory, by path
+type mapStore map[string]string
+
+func (s mapStore) Read(filename string) (string, error) {
+	content, ok := s[filename]
+	if !ok {
+		return "", errors.New(filename + ": not found")
+	}
+	return content, nil
+}
+
+func (s mapStore) List() ([]string, error) {
+	var files []string
+	for f := range s {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (s mapStore) Write(filename, content string) error {
+	s[filename] = content
+	return nil
+}
+
+func TestLoadAllSkipsBrokenFiles(t *testing.T) {
+	s := mapStore{
+		"Issues/issue-001-ok.md":     "---\nid: issue-001\ntype: issue\nstatus: open\n---\n# Issue: OK\n",
+		"Issues/issue-002-broken.md": "---\nid: issue-002\nstatus: open\n",
+		"Issues/issue-003-legacy.md": "# Issue: Legacy\n\n**Status**: Open\n",
+	}
+
+	all, err := LoadAll(s, TypeIssue)
+	var bad FileErrors
+	if !errors.As(err, &bad) || len(bad) != 1 || !strings.Contains(bad[0].Error(), "Issues/issue-002-broken.md") {
+		t.Fatalf("LoadAll() error = %v, want the broken file reported", err)
+	}
+	if len(all) != 2 || all[0].ID != "issue-001" || all[1].ID != "issue-003" {
+		t.Errorf("LoadAll() = %+v, want issue-001 and issue-003", all)
+	}
+
+	if item, err := Find(s, "issue-003"); err != nil || item == nil || item.Title() != "Legacy" {
+		t.Errorf("Find(issue-003) = %+v, %v, want the legacy issue", item, err)
+	}
+	if item, err := Find(s, "issue-002"); item != nil || !errors.As(err, &bad) {
+		t.Errorf("Find(issue-002) = %+v, %v, want the broken file reported", item, err)
+	}
+}
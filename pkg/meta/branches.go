@@ -0,0 +1,214 @@
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RegistryFile maps every branch directory on the shadow branch to its git
+// branch name. Git refuses ref names starting with a dot, so it can never
+// collide with a branch directory.
+const RegistryFile = ".branches.json"
+
+var (
+	branchDirEncoder = strings.NewReplacer("%", "%25", "/", "%2F")
+	branchDirDecoder = strings.NewReplacer("%2F", "/", "%25", "%")
+)
+
+// getBranchMetaDir returns the META directory for a branch. Slashes are
+// percent-encoded, so the mapping is reversible and distinct branches
+// never share a directory; names without '/' or '%' are used as-is.
+func getBranchMetaDir(branch string) string {
+	return branchDirEncoder.Replace(branch)
+}
+
+// branchFromMetaDir reverses getBranchMetaDir
+func branchFromMetaDir(dir string) string {
+	return branchDirDecoder.Replace(dir)
+}
+
+// legacyBranchMetaDir is the lossy mapping used before the registry,
+// replacing '/' with '_'
+func legacyBranchMetaDir(branch string) string {
+	return strings.ReplaceAll(branch, "/", "_")
+}
+
+// BranchRegistry maps branch directories to git branch names
+type BranchRegistry map[string]string
+
+// parseBranchRegistry parses the content of RegistryFile
+func parseBranchRegistry(content string) (BranchRegistry, error) {
+	registry := make(BranchRegistry)
+	if strings.TrimSpace(content) == "" {
+		return registry, nil
+	}
+	if err := json.Unmarshal([]byte(content), &registry); err != nil {
+		return nil, corrupt(RegistryFile, "%v", err)
+	}
+	return registry, nil
+}
+
+// String renders the registry in the format stored in RegistryFile
+func (r BranchRegistry) String() string {
+	data, _ := json.MarshalIndent(r, "", "  ")
+	return string(data) + "\n"
+}
+
+// readBranchRegistry reads the registry from a shadow branch commit.
+// The second return value reports whether the registry file exists.
+func readBranchRegistry(ctx context.Context, commit string) (BranchRegistry, bool, error) {
+	content, ok, err := readBlob(ctx, commit, RegistryFile)
+	if err != nil {
+		return nil, false, err
+	}
+	registry, err := parseBranchRegistry(content)
+	return registry, ok, err
+}
+
+// ReadBranchRegistry returns the branch registry of the shadow branch
+func ReadBranchRegistry(ctx context.Context) (BranchRegistry, error) {
+	if !IsInitialized(ctx) {
+		return nil, ErrNotInitialized
+	}
+	registry, _, err := readBranchRegistry(ctx, resolveShadowTip(ctx))
+	return registry, err
+}
+
+// registerBranch adds the branch to the registry as part of a pending
+// shadow commit on top of parent, if it isn't registered yet
+func registerBranch(ctx context.Context, parent string, changes map[string]*string, branch, dir string) error {
+	registry, _, err := readBranchRegistry(ctx, parent)
+	if err != nil {
+		return err
+	}
+	if registry[dir] == branch {
+		return nil
+	}
+	registry[dir] = branch
+	content := registry.String()
+	changes[RegistryFile] = &content
+	return nil
+}
+
+// NeedsMigration reports whether the shadow branch predates the branch
+// registry and its directories may still use the legacy naming
+func NeedsMigration(ctx context.Context) bool {
+	tip := resolveShadowTip(ctx)
+	if tip == "" {
+		return false
+	}
+	_, ok, err := readBlob(ctx, tip, RegistryFile)
+	return err == nil && !ok
+}
+
+// Migration reports what MigrateBranchDirs did
+type Migration struct {
+	// Renamed maps legacy directory names to their new names
+	Renamed map[string]string
+	// Registered lists directories whose name already matched their branch
+	Registered []string
+	// Unresolved maps directories that couldn't be assigned to exactly one
+	// git branch to the candidate branches, which may be empty
+	Unresolved map[string][]string
+}
+
+// MigrateBranchDirs assigns every unregistered directory on the shadow
+// branch to a local git branch, renaming directories created with the
+// legacy '/' to '_' mapping, and records them in the registry in one
+// commit. Directories that match no branch or several are left alone.
+func MigrateBranchDirs(ctx context.Context) (*Migration, error) {
+	if !IsInitialized(ctx) {
+		return nil, ErrNotInitialized
+	}
+
+	output, err := runGit(ctx, "", "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for _, branch := range strings.Fields(output) {
+		if branch != BranchName {
+			branches = append(branches, branch)
+		}
+	}
+
+	var migration *Migration
+	commitMsg := (&Trailers{Operation: OpMigrate}).Message("Migrate LadderMoon META branch directories")
+	err = commitShadow(ctx, commitMsg, func(parent string) (map[string]*string, error) {
+		migration = &Migration{Renamed: make(map[string]string), Unresolved: make(map[string][]string)}
+
+		registry, _, err := readBranchRegistry(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := lsTree(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		dirs := make(map[string]bool)
+		for _, e := range entries {
+			if e.typ == "tree" {
+				dirs[e.name] = true
+			}
+		}
+
+		changes := make(map[string]*string)
+		blobs, err := listBlobs(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			dir := e.name
+			if e.typ != "tree" || registry[dir] != "" || strings.HasPrefix(dir, ".") {
+				continue
+			}
+
+			var exact string
+			var candidates []string
+			for _, branch := range branches {
+				if getBranchMetaDir(branch) == dir {
+					exact = branch
+				} else if legacyBranchMetaDir(branch) == dir {
+					candidates = append(candidates, branch)
+				}
+			}
+
+			switch {
+			case exact != "":
+				registry[dir] = exact
+				migration.Registered = append(migration.Registered, dir)
+			case len(candidates) == 1 && !dirs[getBranchMetaDir(candidates[0])]:
+				target := getBranchMetaDir(candidates[0])
+				for p := range blobs {
+					rel, ok := strings.CutPrefix(p, dir+"/")
+					if !ok {
+						continue
+					}
+					content, _, err := readBlob(ctx, parent, p)
+					if err != nil {
+						return nil, err
+					}
+					changes[path.Join(target, rel)] = &content
+					changes[p] = nil
+				}
+				dirs[target] = true
+				registry[target] = candidates[0]
+				migration.Renamed[dir] = target
+			default:
+				migration.Unresolved[dir] = candidates
+			}
+		}
+
+		content := registry.String()
+		changes[RegistryFile] = &content
+		return changes, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate META directories: %w", err)
+	}
+	return migration, nil
+}
@@ -0,0 +1,125 @@
+package meta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// DecisionLog is the file in META that records the user's triage decisions,
+// one JSON object per line
+const DecisionLog = "Decisions.jsonl"
+
+// OpDecision marks commits that record a triage decision
+const OpDecision = "decision"
+
+// Triage choices
+const (
+	ChoiceApprove = "approve"
+	ChoiceReject  = "reject"
+	ChoiceSkip    = "skip"
+)
+
+// Decision is what the user decided about an item in triage, and why
+type Decision struct {
+	// Item is the ID of the decided item
+	Item string `json:"item"`
+	// Category is the item's type and, if it has one, its category,
+	// e.g. "issue/intent-conflict"
+	Category string `json:"category"`
+	// Choice is one of approve, reject or skip
+	Choice string `json:"choice"`
+	// Reason is the user's free-text explanation, if they gave one
+	Reason string `json:"reason,omitempty"`
+	// Task is the ID of the task an approval created
+	Task string `json:"task,omitempty"`
+	// Priority is the priority an approved item and its task got
+	Priority string `json:"priority,omitempty"`
+	// Time is when the decision was made
+	Time time.Time `json:"time"`
+	// User is the git user who decided
+	User string `json:"user"`
+	// CodeCommit is the code commit the item was decided at
+	CodeCommit string `json:"code_commit,omitempty"`
+	// MetaCommit is the META commit the item was decided at
+	MetaCommit string `json:"meta_commit,omitempty"`
+}
+
+// Triage records the user's decision about an item in the decision log and
+// applies it, in one commit: an approved item gets a task linked to it,
+// and a rejected item moves to rejected so it isn't filed again. A skipped
+// item stays as it is. It returns the task an approval created.
+func Triage(ctx context.Context, s MetaStore, item *items.Item, choice, actor, reason string) (*items.Item, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	d := &Decision{
+		Item:     item.ID,
+		Category: string(item.Type),
+		Choice:   choice,
+		Reason:   reason,
+		Time:     now,
+		User:     actor,
+	}
+	if category := item.Get("category"); category != "" {
+		d.Category += "/" + category
+	}
+	// The commits are context; a decision is still worth recording without them
+	d.CodeCommit, _ = GetCurrentCommitID(ctx)
+	if _, ok := s.(*ShadowStore); ok {
+		d.MetaCommit, _ = GetMetaBranchCommitID(ctx)
+	}
+
+	var task *items.Item
+	trailers := &Trailers{Operation: OpDecision, Items: []string{item.ID}}
+	var subject string
+	switch choice {
+	case ChoiceApprove:
+		from := item.Status
+		var err error
+		if task, err = stageTask(s, item, actor, orDefault(reason, "Approved in triage"), now); err != nil {
+			s.Rollback()
+			return nil, err
+		}
+		d.Task, d.Priority = task.ID, item.Priority
+		trailers = &Trailers{Operation: OpTask, Items: []string{task.ID, item.ID}}
+		subject = fmt.Sprintf("Create %s from %s %s (%s -> %s)", task.ID, item.Type, item.ID, from, items.StatusTaskCreated)
+	case ChoiceReject:
+		if err := item.SetStatus(items.StatusRejected, actor, orDefault(reason, "Rejected in triage"), now); err != nil {
+			return nil, err
+		}
+		if err := saveItems(s, item); err != nil {
+			s.Rollback()
+			return nil, err
+		}
+		subject = fmt.Sprintf("Reject %s %s", item.Type, item.ID)
+	case ChoiceSkip:
+		subject = fmt.Sprintf("Skip %s %s", item.Type, item.ID)
+	default:
+		return nil, fmt.Errorf("unknown triage choice %q", choice)
+	}
+
+	// Keep the <email> of the user readable
+	var line strings.Builder
+	enc := json.NewEncoder(&line)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(d); err != nil {
+		s.Rollback()
+		return nil, err
+	}
+	if err := s.Append(DecisionLog, line.String()); err != nil {
+		s.Rollback()
+		return nil, err
+	}
+	return task, s.Commit(trailers.Message(subject))
+}
+
+// orDefault returns s, or def if s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
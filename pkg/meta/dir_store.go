@@ -0,0 +1,207 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirName is the directory in the project root used by DirStore
+const DirName = ".laddermoon"
+
+// dirLayout maps top-level names of the shadow branch layout to their
+// names in the .laddermoon/ directory layout
+var dirLayout = map[string]string{
+	MetaFileName:  "meta.md",
+	SyncStateFile: "meta-commit-id",
+	"Questions":   "questions",
+	"Issues":      "issues",
+	"Proposals":   "proposals",
+	"Tasks":       "tasks",
+	// Listed so MoveLegacyProposals finds the files to move
+	legacyProposalDir: "suggestions",
+}
+
+// DirStore keeps META in the .laddermoon/ directory of the working tree.
+// Files travel with the code branch; committing them to git is left to
+// the user, so Commit only writes the staged changes to disk.
+type DirStore struct {
+	ctx  context.Context
+	root string
+	ops  []txOp
+}
+
+// NewDirStore opens the .laddermoon/ directory of the current repository
+func NewDirStore(ctx context.Context) (*DirStore, error) {
+	gitRoot, err := GetGitRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &DirStore{ctx: ctx, root: filepath.Join(gitRoot, DirName)}, nil
+}
+
+// toDisk converts a store path to its relative path in the directory layout
+func (s *DirStore) toDisk(filename string) string {
+	first, rest, nested := strings.Cut(filename, "/")
+	if mapped, ok := dirLayout[first]; ok {
+		first = mapped
+	}
+	if nested {
+		return path.Join(first, rest)
+	}
+	return first
+}
+
+// fromDisk converts a relative path in the directory layout to a store path
+func (s *DirStore) fromDisk(rel string) string {
+	first, rest, nested := strings.Cut(rel, "/")
+	for name, mapped := range dirLayout {
+		if mapped == first {
+			first = name
+			break
+		}
+	}
+	if nested {
+		return path.Join(first, rest)
+	}
+	return first
+}
+
+// Location describes where the store keeps its files
+func (s *DirStore) Location() string {
+	return DirName + "/"
+}
+
+// Initialized reports whether .laddermoon/meta.md exists
+func (s *DirStore) Initialized() bool {
+	_, err := os.Stat(filepath.Join(s.root, filepath.FromSlash(s.toDisk(MetaFileName))))
+	return err == nil
+}
+
+// Init creates meta.md and the item directories
+func (s *DirStore) Init() error {
+	s.Write(MetaFileName, "")
+	for _, dir := range []string{"Questions", "Issues", "Proposals"} {
+		s.Write(path.Join(dir, ".gitkeep"), "")
+	}
+	return s.Commit((&Trailers{Operation: OpInit}).Message("Initialize LadderMoon META"))
+}
+
+// Read returns the content of a file, or ErrNotFound if it doesn't exist
+func (s *DirStore) Read(filename string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(s.root, filepath.FromSlash(s.toDisk(filename))))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", notFound(filename)
+		}
+		return "", fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return string(content), nil
+}
+
+// List returns the paths of all files in the directory
+func (s *DirStore) List() ([]string, error) {
+	if !s.Initialized() {
+		return nil, ErrNotInitialized
+	}
+
+	var result []string
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		result = append(result, s.fromDisk(filepath.ToSlash(rel)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list META files: %w", err)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Write stages overwriting a file with content
+func (s *DirStore) Write(filename, content string) error {
+	s.ops = append(s.ops, txOp{kind: txWrite, filename: filename, content: content})
+	return nil
+}
+
+// Append stages appending content to a file
+func (s *DirStore) Append(filename, content string) error {
+	s.ops = append(s.ops, txOp{kind: txAppend, filename: filename, content: content})
+	return nil
+}
+
+// Delete stages removing a file
+func (s *DirStore) Delete(filename string) error {
+	s.ops = append(s.ops, txOp{kind: txDelete, filename: filename})
+	return nil
+}
+
+// History returns the code branch commits that changed a file
+func (s *DirStore) History(filename string) ([]Revision, error) {
+	return gitHistory(s.ctx, "HEAD", "--", filepath.Join(s.root, filepath.FromSlash(s.toDisk(filename))))
+}
+
+// Rollback discards all staged changes
+func (s *DirStore) Rollback() {
+	s.ops = nil
+}
+
+// Commit writes all staged changes to disk. Every file is written to a
+// temporary file first and renamed into place, so readers never observe
+// a partially written file.
+func (s *DirStore) Commit(message string) error {
+	ops := s.ops
+	s.ops = nil
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	changes, err := applyOps(ops, func(filename string) (string, error) {
+		content, err := s.Read(filename)
+		if errors.Is(err, ErrNotFound) {
+			return "", nil // Missing files start empty
+		}
+		return content, err
+	})
+	if err != nil {
+		return err
+	}
+
+	for filename, content := range changes {
+		target := filepath.Join(s.root, filepath.FromSlash(s.toDisk(filename)))
+		if content == nil {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete %s: %w", filename, err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		tmp := target + ".lm-tmp"
+		if err := os.WriteFile(tmp, []byte(*content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+		if err := os.Rename(tmp, target); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+	return nil
+}
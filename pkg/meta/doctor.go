@@ -0,0 +1,84 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// OpDoctor marks commits made by lm doctor --fix
+const OpDoctor = "doctor"
+
+// HighestFeedID returns the highest feed ID recorded in UserFeed.log,
+// or 0 if no feed was recorded
+func HighestFeedID(s MetaStore) (int, error) {
+	content, err := s.Read(UserFeedLog)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return maxFeedID(content), nil
+}
+
+// SetNextFeedID overwrites the feed counter, for repairing it
+func SetNextFeedID(s MetaStore, next int) error {
+	if err := s.Write(FeedIDFile, strconv.Itoa(next)+"\n"); err != nil {
+		return err
+	}
+	trailers := &Trailers{Operation: OpDoctor}
+	return s.Commit(trailers.Message(fmt.Sprintf("Repair %s to %d", FeedIDFile, next)))
+}
+
+// ClearSyncedCommitID removes the sync state, so the next sync starts over
+func ClearSyncedCommitID(s MetaStore) error {
+	if err := s.Delete(SyncStateFile); err != nil {
+		return err
+	}
+	trailers := &Trailers{Operation: OpDoctor}
+	return s.Commit(trailers.Message(fmt.Sprintf("Clear %s", SyncStateFile)))
+}
+
+// CommitExists reports whether a commit exists in the repository
+func CommitExists(ctx context.Context, commit string) bool {
+	if commit == "" {
+		return false
+	}
+	_, err := runGit(ctx, "", "cat-file", "-e", commit+"^{commit}")
+	return err == nil
+}
+
+// StaleLockHolder returns the holder recorded in the lock file when it is
+// a process on this host that no longer runs, or nil. Holders on other
+// hosts can't be checked and are never reported.
+func StaleLockHolder(ctx context.Context) (*LockHolder, error) {
+	holder, err := ReadLockHolder(ctx)
+	if err != nil || holder == nil {
+		return nil, err
+	}
+	if !holder.IsLocal() || holder.Alive() {
+		return nil, nil
+	}
+	return holder, nil
+}
+
+// MissingSkills returns the skills not installed in .claude/skills
+func MissingSkills(ctx context.Context, skillNames []string) ([]string, error) {
+	gitRoot, err := GetGitRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, name := range skillNames {
+		skillFile := filepath.Join(gitRoot, ".claude", "skills", name, "SKILL.md")
+		if _, err := os.Stat(skillFile); err != nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,75 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	ErrNotGitRepo     = errors.New("not a git repository")
+	ErrAlreadyInit    = errors.New("laddermoon already initialized (branch laddermoon-meta exists)")
+	ErrNotInitialized = errors.New("laddermoon not initialized, run 'lm init' first")
+	ErrDetachedHead   = errors.New("HEAD is detached, check out a branch first")
+	// ErrNotFound is returned when a META file or item doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrCorrupt is returned when a META file can't be parsed
+	ErrCorrupt = errors.New("corrupt META data")
+	// ErrGit matches every GitError with errors.Is
+	ErrGit = errors.New("git command failed")
+)
+
+// GitError describes a failed git command
+type GitError struct {
+	// Args are the arguments git was run with
+	Args []string
+	// Stderr is what git printed to standard error
+	Stderr string
+	// ExitCode is the exit code of git, or -1 if it didn't run
+	ExitCode int
+	Err      error
+}
+
+func (e *GitError) Error() string {
+	command := "git"
+	if len(e.Args) > 0 {
+		command += " " + e.Args[0]
+	}
+	if e.ExitCode < 0 {
+		return fmt.Sprintf("%s failed: %v", command, e.Err)
+	}
+	if e.Stderr == "" {
+		return fmt.Sprintf("%s failed with exit code %d", command, e.ExitCode)
+	}
+	return fmt.Sprintf("%s failed with exit code %d: %s", command, e.ExitCode, e.Stderr)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every GitError match ErrGit
+func (e *GitError) Is(target error) bool {
+	return target == ErrGit
+}
+
+// newGitError wraps the error of a git command run with args
+func newGitError(args []string, stderr string, err error) *GitError {
+	code := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	}
+	return &GitError{Args: args, Stderr: strings.TrimSpace(stderr), ExitCode: code, Err: err}
+}
+
+// notFound returns an ErrNotFound for a META file or item
+func notFound(name string) error {
+	return fmt.Errorf("%s: %w", name, ErrNotFound)
+}
+
+// corrupt returns an ErrCorrupt for a META file that can't be parsed
+func corrupt(name string, format string, args ...any) error {
+	return fmt.Errorf("%s: %w: %s", name, ErrCorrupt, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,294 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// ForkFile records where a branch's META was forked from
+const ForkFile = ".fork"
+
+// ErrNoParentBranch is returned when no branch with META can be forked from
+var ErrNoParentBranch = errors.New("no parent branch with LadderMoon META found")
+
+// forkedItemDirs are the item directories copied when forking
+var forkedItemDirs = []string{"Questions", "Issues", "Proposals", "Tasks"}
+
+// ForkPoint describes the origin of a forked META directory
+type ForkPoint struct {
+	// Parent is the git branch the META was forked from
+	Parent string
+	// MetaCommit is the shadow branch commit the fork was taken from,
+	// the common ancestor for later merges of the two META directories
+	MetaCommit string
+	// CodeCommit is the merge-base of the two git branches at fork time
+	CodeCommit string
+	// Time is when the fork happened
+	Time time.Time
+}
+
+// String renders the fork point in the format stored in ForkFile
+func (f *ForkPoint) String() string {
+	return fmt.Sprintf("parent: %s\nmeta-commit: %s\ncode-commit: %s\ntime: %d\n",
+		f.Parent, f.MetaCommit, f.CodeCommit, f.Time.Unix())
+}
+
+// ParseForkPoint parses the content of ForkFile
+func ParseForkPoint(content string) (*ForkPoint, error) {
+	f := &ForkPoint{}
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "parent":
+			f.Parent = value
+		case "meta-commit":
+			f.MetaCommit = value
+		case "code-commit":
+			f.CodeCommit = value
+		case "time":
+			unix, _ := strconv.ParseInt(value, 10, 64)
+			f.Time = time.Unix(unix, 0)
+		}
+	}
+	if f.Parent == "" || f.MetaCommit == "" {
+		return nil, corrupt(ForkFile, "parent or meta-commit missing")
+	}
+	return f, nil
+}
+
+// ReadForkPoint returns where the store's META was forked from,
+// or nil if it was initialized empty
+func ReadForkPoint(s MetaStore) (*ForkPoint, error) {
+	content, err := s.Read(ForkFile)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil || strings.TrimSpace(content) == "" {
+		return nil, err
+	}
+	return ParseForkPoint(content)
+}
+
+// MetaBranches returns the git branches that have a META directory on the shadow branch
+func MetaBranches(ctx context.Context) ([]string, error) {
+	if !IsInitialized(ctx) {
+		return nil, ErrNotInitialized
+	}
+
+	registry, err := ReadBranchRegistry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := lsTree(ctx, shadowRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, e := range entries {
+		branch := registry[e.name]
+		if e.typ == "tree" && branch != "" && BranchExists(ctx, branch) {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// DetectParentBranch picks the branch with META whose history the current
+// branch forked from most recently, measured in commits since the merge-base
+func DetectParentBranch(ctx context.Context) (string, error) {
+	current, err := GetCurrentBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+	branches, err := MetaBranches(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	best, bestDistance := "", -1
+	for _, branch := range branches {
+		if branch == current {
+			continue
+		}
+		base, err := runGitTrimmed(ctx, "", "merge-base", "HEAD", branch)
+		if err != nil {
+			continue
+		}
+		count, err := runGitTrimmed(ctx, "", "rev-list", "--count", base+"..HEAD")
+		if err != nil {
+			continue
+		}
+		distance, _ := strconv.Atoi(count)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = branch, distance
+		}
+	}
+
+	if best == "" {
+		return "", ErrNoParentBranch
+	}
+	return best, nil
+}
+
+// Fork initializes the store's META directory as a copy of the parent
+// branch's META.md, sync state, feed log, decision log and open items, and
+// records the fork point. Closed items that forked items link to, directly
+// or through other closed items, are copied too, so no link dangles. The
+// item indexes are rebuilt for the copied items. Everything lands in one
+// shadow branch commit.
+func (s *ShadowStore) Fork(parentBranch string) (*ForkPoint, error) {
+	if !IsInitialized(s.ctx) {
+		return nil, ErrNotInitialized
+	}
+	if parentBranch == s.branch {
+		return nil, fmt.Errorf("cannot fork META of branch %s from itself", s.branch)
+	}
+
+	parentDir := getBranchMetaDir(parentBranch)
+	codeCommit, _ := runGitTrimmed(s.ctx, "", "merge-base", "HEAD", parentBranch)
+
+	var fork *ForkPoint
+	commitMsg := (&Trailers{Operation: OpFork}).Message(fmt.Sprintf("Fork LadderMoon META for branch: %s from %s", s.branch, parentBranch))
+	err := commitShadow(s.ctx, commitMsg, func(parent string) (map[string]*string, error) {
+		blobs, err := listBlobs(s.ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+
+		files := make(map[string]string)
+		for p := range blobs {
+			rel, ok := strings.CutPrefix(p, parentDir+"/")
+			if !ok {
+				continue
+			}
+			// A parent that wasn't migrated yet forks as if it had been
+			if moved, ok := migratedPath(rel); ok {
+				rel = moved
+			}
+			if !isForkedFile(rel) {
+				continue
+			}
+			content, _, err := readBlob(s.ctx, parent, p)
+			if err != nil {
+				return nil, err
+			}
+			files[rel] = content
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("branch %s has no META to fork", parentBranch)
+		}
+
+		changes := make(map[string]*string)
+		forked, left := forkedItems(files)
+		for rel, content := range files {
+			if left[rel] {
+				continue
+			}
+			content := content
+			changes[path.Join(s.branchDir, rel)] = &content
+		}
+		for _, typ := range items.Types {
+			var entries []IndexEntry
+			for _, item := range forked {
+				if item.Type == typ {
+					entries = append(entries, indexEntry(item))
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			index, err := formatIndex(entries)
+			if err != nil {
+				return nil, err
+			}
+			changes[path.Join(s.branchDir, IndexFile(typ))] = &index
+		}
+
+		fork = &ForkPoint{Parent: parentBranch, MetaCommit: parent, CodeCommit: codeCommit, Time: time.Now()}
+		record := fork.String()
+		changes[path.Join(s.branchDir, ForkFile)] = &record
+		if err := registerBranch(s.ctx, parent, changes, s.branch, s.branchDir); err != nil {
+			return nil, err
+		}
+		return changes, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork META: %w", err)
+	}
+	return fork, nil
+}
+
+// forkedItems sorts the items among the parent's files into the ones a
+// fork copies, keyed by path: the open ones and the closed ones they link
+// to, transitively; and the paths of the closed ones it leaves behind.
+// Item files that don't parse are in neither and are copied as they are.
+func forkedItems(files map[string]string) (map[string]*items.Item, map[string]bool) {
+	byID := make(map[string]*items.Item)
+	forked := make(map[string]*items.Item)
+	left := make(map[string]bool)
+	var queue []*items.Item
+	for rel, content := range files {
+		if _, ok := items.TypeOfPath(rel); !ok {
+			continue
+		}
+		item, err := items.ParseFile(rel, content)
+		if err != nil {
+			continue
+		}
+		byID[item.ID] = item
+		if item.IsClosed() {
+			left[rel] = true
+			continue
+		}
+		forked[rel] = item
+		queue = append(queue, item)
+	}
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		for _, link := range item.Links {
+			target := byID[link.Target]
+			if target != nil && forked[target.Path] == nil {
+				forked[target.Path] = target
+				delete(left, target.Path)
+				queue = append(queue, target)
+			}
+		}
+	}
+	return forked, left
+}
+
+// isItemFile reports whether a META path lies in an item directory
+func isItemFile(rel string) bool {
+	dir, _, ok := strings.Cut(rel, "/")
+	if !ok {
+		return false
+	}
+	for _, d := range forkedItemDirs {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// isForkedFile reports whether a META path is carried over by Fork
+func isForkedFile(rel string) bool {
+	switch rel {
+	case MetaFileName, SyncStateFile, UserFeedLog, FeedIDFile, DecisionLog:
+		return true
+	}
+	return isItemIDFile(rel) || isItemFile(rel) && !isIndexFile(rel)
+}
@@ -0,0 +1,73 @@
+package meta
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+func TestFork(t *testing.T) {
+	parent := setupTestRepo(t)
+	task := func(id, status string, links ...items.Link) *items.Item {
+		return &items.Item{ID: id, Type: items.TypeTask, Status: status, Links: links, Body: "# Task: " + id + "\n"}
+	}
+	// task-002 is held up by task-001, which was done and was itself held
+	// up by task-004; task-003 is done and nothing links to it
+	for _, item := range []*items.Item{
+		task("task-001", items.StatusDone, items.Link{Rel: items.LinkBlockedBy, Target: "task-004"}),
+		task("task-002", items.StatusOpen, items.Link{Rel: items.LinkBlockedBy, Target: "task-001"}),
+		task("task-003", items.StatusDone),
+		task("task-004", items.StatusCancelled),
+	} {
+		if err := items.Save(parent, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parent.Write(MetaFileName, "Uses PostgreSQL\n")
+	parent.Write(DecisionLog, `{"item":"issue-001","choice":"confirm"}`+"\n")
+	if err := parent.Commit("Seed META"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RebuildIndexes(parent, items.TypeTask); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := exec.Command("git", "checkout", "-q", "-b", "feature").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout: %v: %s", err, out)
+	}
+	store, err := NewShadowStore(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Fork("main"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{MetaFileName, DecisionLog} {
+		if _, err := store.Read(f); err != nil {
+			t.Errorf("%s was not forked: %v", f, err)
+		}
+	}
+	all, err := items.LoadAll(store, items.TypeTask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range all {
+		ids = append(ids, item.ID)
+	}
+	if want := []string{"task-001", "task-002", "task-004"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("forked tasks = %v, want %v", ids, want)
+	}
+
+	entries, found, err := ReadIndex(store, items.TypeTask)
+	if err != nil || !found || len(entries) != 3 {
+		t.Fatalf("ReadIndex() = %+v, %v, %v, want the three forked tasks", entries, found, err)
+	}
+	if stale, err := StaleIndexes(store); err != nil || len(stale) != 0 {
+		t.Errorf("StaleIndexes() = %v, %v, want none", stale, err)
+	}
+}
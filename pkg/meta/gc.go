@@ -0,0 +1,413 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ArchiveDir holds the directories of deleted branches archived by gc.
+	// Like RegistryFile it starts with a dot and never collides with a branch.
+	ArchiveDir = ".archive"
+	// TempWorktreePrefix is the name prefix of the worktrees skills create
+	TempWorktreePrefix = ".lm-tmp-"
+	// TempWorktreeMinAge protects worktrees a running skill may still use
+	TempWorktreeMinAge = time.Hour
+	// OpGC marks commits made by lm gc
+	OpGC = "gc"
+)
+
+// ErrSharedHistory is returned when squashing a shadow branch that was
+// already pushed, since teammates could no longer pull or push it
+var ErrSharedHistory = errors.New("META history is shared with a remote")
+
+// StaleDir is a branch directory whose git branch no longer exists
+type StaleDir struct {
+	Dir    string
+	Branch string
+	// LastChange is when the directory was last changed
+	LastChange time.Time
+}
+
+// StaleBranchDirs returns the branch directories on the shadow branch
+// whose git branch has been deleted
+func StaleBranchDirs(ctx context.Context) ([]StaleDir, error) {
+	if !IsInitialized(ctx) {
+		return nil, ErrNotInitialized
+	}
+
+	registry, err := ReadBranchRegistry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := lsTree(ctx, shadowRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []StaleDir
+	for _, e := range entries {
+		if e.typ != "tree" || strings.HasPrefix(e.name, ".") {
+			continue
+		}
+		branch := registry[e.name]
+		if branch == "" {
+			branch = branchFromMetaDir(e.name)
+		}
+		if BranchExists(ctx, branch) {
+			continue
+		}
+		dir := StaleDir{Dir: e.name, Branch: branch}
+		if unix, err := runGitTrimmed(ctx, "", "log", "-1", "--format=%at", shadowRef, "--", e.name); err == nil {
+			seconds, _ := strconv.ParseInt(unix, 10, 64)
+			dir.LastChange = time.Unix(seconds, 0)
+		}
+		stale = append(stale, dir)
+	}
+	return stale, nil
+}
+
+// ArchiveBranchDirs moves branch directories under ArchiveDir and drops
+// them from the registry in one commit
+func ArchiveBranchDirs(ctx context.Context, dirs []string) error {
+	return removeBranchDirs(ctx, dirs, true)
+}
+
+// DeleteBranchDirs removes branch directories and drops them from the
+// registry in one commit. Their content stays reachable in history.
+func DeleteBranchDirs(ctx context.Context, dirs []string) error {
+	return removeBranchDirs(ctx, dirs, false)
+}
+
+func removeBranchDirs(ctx context.Context, dirs []string, archive bool) error {
+	if len(dirs) == 0 {
+		return nil
+	}
+	if !IsInitialized(ctx) {
+		return ErrNotInitialized
+	}
+
+	verb := "Delete"
+	if archive {
+		verb = "Archive"
+	}
+	subject := fmt.Sprintf("%s META of deleted branches: %s", verb, strings.Join(dirs, ", "))
+	commitMsg := (&Trailers{Operation: OpGC}).Message(subject)
+
+	err := commitShadow(ctx, commitMsg, func(parent string) (map[string]*string, error) {
+		blobs, err := listBlobs(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		registry, _, err := readBranchRegistry(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+
+		changes := make(map[string]*string)
+		for _, dir := range dirs {
+			for p := range blobs {
+				if !strings.HasPrefix(p, dir+"/") {
+					continue
+				}
+				if archive {
+					content, _, err := readBlob(ctx, parent, p)
+					if err != nil {
+						return nil, err
+					}
+					changes[path.Join(ArchiveDir, p)] = &content
+				}
+				changes[p] = nil
+			}
+			delete(registry, dir)
+		}
+		content := registry.String()
+		changes[RegistryFile] = &content
+		return changes, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to %s META directories: %w", strings.ToLower(verb), err)
+	}
+	return nil
+}
+
+// Squash reports what SquashHistory did
+type Squash struct {
+	// Snapshot is the commit replacing the history before the cutoff
+	Snapshot string
+	// Squashed is the number of commits folded into snapshots, not
+	// counting the tagged commits and base kept as snapshots of their own
+	Squashed int
+	// Kept is the number of commits after the cutoff that were rewritten
+	// on top of the snapshot
+	Kept int
+	// Retagged maps tags that were moved to their new commits
+	Retagged map[string]string
+}
+
+// SharedMetaRemotes returns the remotes the shadow branch was fetched from or pushed to
+func SharedMetaRemotes(ctx context.Context) []string {
+	output, err := runGit(ctx, "", "for-each-ref", "--format=%(refname)", "refs/remotes")
+	if err != nil {
+		return nil
+	}
+	var remotes []string
+	for _, ref := range strings.Fields(output) {
+		rest, ok := strings.CutPrefix(ref, "refs/remotes/")
+		if ok && strings.HasSuffix(rest, "/"+BranchName) {
+			remotes = append(remotes, strings.TrimSuffix(rest, "/"+BranchName))
+		}
+	}
+	return remotes
+}
+
+// SquashHistory replaces the shadow branch history before cutoff with a
+// single snapshot commit. Tagged commits before the cutoff are kept as
+// snapshots of their own, and later commits are rewritten on top with
+// their original trees, messages and dates, following first parents.
+// Tags pointing into the rewritten history are moved to the new commits.
+// Unless force is set, history that was shared with a remote is refused.
+func SquashHistory(ctx context.Context, cutoff time.Time, force bool) (*Squash, error) {
+	if !IsInitialized(ctx) {
+		return nil, ErrNotInitialized
+	}
+	if remotes := SharedMetaRemotes(ctx); len(remotes) > 0 && !force {
+		return nil, fmt.Errorf("%w (%s)", ErrSharedHistory, strings.Join(remotes, ", "))
+	}
+
+	tip := resolveShadowTip(ctx)
+	base, err := runGitTrimmed(ctx, "", "rev-list", "-1", "--first-parent", fmt.Sprintf("--before=%d", cutoff.Unix()), tip)
+	if err != nil {
+		return nil, err
+	}
+	result := &Squash{Retagged: make(map[string]string)}
+	if base == "" {
+		return result, nil
+	}
+
+	old, err := revList(ctx, "--reverse", base)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := revList(ctx, "--reverse", "--first-parent", base+".."+tip)
+	if err != nil {
+		return nil, err
+	}
+	if len(old) <= 1 {
+		return result, nil
+	}
+
+	tags, err := shadowTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewrite: tagged old commits become a chain of snapshots ending with
+	// the snapshot of base, then the recent commits follow
+	rewritten := make(map[string]string)
+	parent := ""
+	for _, commit := range old {
+		if commit != base && len(tags[commit]) == 0 {
+			result.Squashed++
+			continue
+		}
+		var subject string
+		if commit == base {
+			subject = fmt.Sprintf("Snapshot META as of %s", cutoff.Format("2006-01-02"))
+		}
+		if parent, err = copyCommit(ctx, commit, parent, subject); err != nil {
+			return nil, err
+		}
+		rewritten[commit] = parent
+	}
+	result.Snapshot = parent
+
+	for _, commit := range recent {
+		if parent, err = copyCommit(ctx, commit, parent, ""); err != nil {
+			return nil, err
+		}
+		rewritten[commit] = parent
+	}
+	result.Kept = len(recent)
+
+	if _, err := runGit(ctx, "", "update-ref", "-m", "Squash META history", shadowRef, parent, tip); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", BranchName, err)
+	}
+
+	for commit, names := range tags {
+		target, ok := rewritten[commit]
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			if err := retag(ctx, name, target); err != nil {
+				return result, err
+			}
+			result.Retagged[name] = target
+		}
+	}
+	return result, nil
+}
+
+// revList returns the commit IDs of a rev-list query
+func revList(ctx context.Context, args ...string) ([]string, error) {
+	output, err := runGit(ctx, "", append([]string{"rev-list"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// shadowTags maps the shadow branch commits that are tagged to their tag names
+func shadowTags(ctx context.Context) (map[string][]string, error) {
+	output, err := runGit(ctx, "", "for-each-ref", "--format=%(refname:short) %(objectname) %(*objectname)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		commit := fields[1]
+		if len(fields) == 3 {
+			commit = fields[2] // Annotated tag, use the tagged commit
+		}
+		if isAncestor(ctx, commit, shadowRef) {
+			tags[commit] = append(tags[commit], fields[0])
+		}
+	}
+	return tags, nil
+}
+
+// copyCommit recreates a commit with the same tree, message, author and
+// dates on top of parent. A non-empty subject replaces the message.
+func copyCommit(ctx context.Context, commit, parent, subject string) (string, error) {
+	info, err := runGit(ctx, "", "show", "-s", "--format=%an%x00%ae%x00%ad%x00%cn%x00%ce%x00%cd%x00%B", "--date=raw", commit)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.SplitN(info, "\x00", 7)
+	if len(fields) != 7 {
+		return "", fmt.Errorf("failed to read commit %s", shortID(commit))
+	}
+
+	message := fields[6]
+	if subject != "" {
+		message = (&Trailers{Operation: OpGC, Session: SessionID()}).Message(subject)
+	}
+	args := []string{"commit-tree", commit + "^{tree}", "-F", "-"}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+
+	cmd := gitCommand(ctx, message, args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+fields[0], "GIT_AUTHOR_EMAIL="+fields[1], "GIT_AUTHOR_DATE="+fields[2],
+		"GIT_COMMITTER_NAME="+fields[3], "GIT_COMMITTER_EMAIL="+fields[4], "GIT_COMMITTER_DATE="+fields[5])
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite commit %s: %w", shortID(commit), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// retag moves a tag to another commit, keeping the message of annotated tags
+func retag(ctx context.Context, name, commit string) error {
+	kind, err := runGitTrimmed(ctx, "", "cat-file", "-t", "refs/tags/"+name)
+	if err != nil {
+		return err
+	}
+	if kind != "tag" {
+		_, err = runGit(ctx, "", "update-ref", "refs/tags/"+name, commit)
+		return err
+	}
+	message, err := runGit(ctx, "", "for-each-ref", "--format=%(contents)", "refs/tags/"+name)
+	if err != nil {
+		return err
+	}
+	_, err = runGit(ctx, message, "tag", "-f", "-a", "-F", "-", name, commit)
+	return err
+}
+
+// TempWorktree is a leftover .lm-tmp-* directory of an interrupted skill
+type TempWorktree struct {
+	Path string
+	// Registered reports whether git still lists it as a worktree
+	Registered bool
+	Created    time.Time
+}
+
+// TempWorktrees returns the .lm-tmp-* directories and worktrees in the
+// project root that are older than minAge
+func TempWorktrees(ctx context.Context, minAge time.Duration) ([]TempWorktree, error) {
+	gitRoot, err := GetGitRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*TempWorktree)
+	output, err := runGit(ctx, "", "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		p, ok := strings.CutPrefix(line, "worktree ")
+		if ok && strings.HasPrefix(filepath.Base(p), TempWorktreePrefix) {
+			found[p] = &TempWorktree{Path: p, Registered: true}
+		}
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(gitRoot, TempWorktreePrefix+"*"))
+	for _, p := range matches {
+		if found[p] == nil {
+			found[p] = &TempWorktree{Path: p}
+		}
+	}
+
+	var result []TempWorktree
+	for _, wt := range found {
+		wt.Created = tempWorktreeTime(wt.Path)
+		if time.Since(wt.Created) >= minAge {
+			result = append(result, *wt)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// tempWorktreeTime returns when a temporary worktree was created, from the
+// unix timestamp older skills put in its name, or its modification time
+func tempWorktreeTime(p string) time.Time {
+	suffix := strings.TrimPrefix(filepath.Base(p), TempWorktreePrefix)
+	if unix, err := strconv.ParseInt(suffix, 10, 64); err == nil {
+		return time.Unix(unix, 0)
+	}
+	if info, err := os.Stat(p); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// RemoveTempWorktree removes a leftover temporary worktree and its directory
+func RemoveTempWorktree(ctx context.Context, wt TempWorktree) error {
+	if wt.Registered {
+		if _, err := runGit(ctx, "", "worktree", "remove", "--force", wt.Path); err == nil {
+			return nil
+		}
+	}
+	if err := os.RemoveAll(wt.Path); err != nil {
+		return err
+	}
+	_, err := runGit(ctx, "", "worktree", "prune")
+	return err
+}
@@ -0,0 +1,146 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNoMetaAt is returned when no META state matches a point in time
+var ErrNoMetaAt = errors.New("no META state found")
+
+// Kinds of points a META state can be looked up by
+const (
+	// AtMetaCommit is a commit of the shadow branch
+	AtMetaCommit = "meta-commit"
+	// AtCodeCommit is a code commit META was synced to
+	AtCodeCommit = "code-commit"
+	// AtDate is a point in time
+	AtDate = "date"
+)
+
+// dateLayouts are the date formats accepted by ResolveAt
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// MetaPoint is the META state of a branch at a point in its history
+type MetaPoint struct {
+	// Commit is the shadow branch commit holding the state
+	Commit string
+	// Kind is how the point was given: AtMetaCommit, AtCodeCommit or AtDate
+	Kind string
+	// SyncedTo is the code commit the state was synced to, if any
+	SyncedTo string
+	// Exact is false for a code commit that META was never synced to
+	// exactly; the state synced to its closest ancestor is used instead
+	Exact bool
+}
+
+// ResolveAt finds the META state of the store's branch at a shadow branch
+// commit, a code commit or a date, tried in that order
+func (s *ShadowStore) ResolveAt(at string) (*MetaPoint, error) {
+	if !IsInitialized(s.ctx) {
+		return nil, ErrNotInitialized
+	}
+
+	if commit, err := runGitTrimmed(s.ctx, "", "rev-parse", "--verify", "--quiet", at+"^{commit}"); err == nil {
+		if isAncestor(s.ctx, commit, shadowRef) {
+			if _, err := runGit(s.ctx, "", "cat-file", "-e", commit+":"+s.branchDir); err != nil {
+				return nil, fmt.Errorf("%w for branch %s at META commit %s", ErrNoMetaAt, s.branch, shortID(commit))
+			}
+			synced, _, err := readBlob(s.ctx, commit, path.Join(s.branchDir, SyncStateFile))
+			if err != nil {
+				return nil, err
+			}
+			return &MetaPoint{Commit: commit, Kind: AtMetaCommit, SyncedTo: strings.TrimSpace(synced), Exact: true}, nil
+		}
+		return s.syncedTo(commit)
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, at, time.Local); err == nil {
+			if layout == "2006-01-02" {
+				// A bare date means the state at the end of that day
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return s.stateAt(t)
+		}
+	}
+	return nil, fmt.Errorf("%q is neither a commit nor a date (use YYYY-MM-DD [HH:MM[:SS]])", at)
+}
+
+// syncedTo finds the shadow branch commit that synced META to the code
+// commit. Without such a sync, the latest sync to one of its ancestors is used.
+func (s *ShadowStore) syncedTo(code string) (*MetaPoint, error) {
+	revisions, err := s.History(SyncStateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revisions are newest first: the last match is when META was
+	// first synced to the commit, before later feeds were recorded
+	var exact *MetaPoint
+	var closest *MetaPoint
+	for _, rev := range revisions {
+		content, _, err := readBlob(s.ctx, rev.ID, path.Join(s.branchDir, SyncStateFile))
+		if err != nil {
+			return nil, err
+		}
+		synced := strings.TrimSpace(content)
+		switch {
+		case synced == "":
+			continue
+		case synced == code:
+			exact = &MetaPoint{Commit: rev.ID, Kind: AtCodeCommit, SyncedTo: synced, Exact: true}
+		case exact == nil && closest == nil && isAncestor(s.ctx, synced, code):
+			closest = &MetaPoint{Commit: rev.ID, Kind: AtCodeCommit, SyncedTo: synced}
+		}
+	}
+
+	if exact != nil {
+		return exact, nil
+	}
+	if closest != nil {
+		return closest, nil
+	}
+	return nil, fmt.Errorf("%w for branch %s: META was never synced to %s or one of its ancestors", ErrNoMetaAt, s.branch, shortID(code))
+}
+
+// stateAt finds the last shadow branch commit changing the branch's META
+// at or before t
+func (s *ShadowStore) stateAt(t time.Time) (*MetaPoint, error) {
+	commit, err := runGitTrimmed(s.ctx, "", "rev-list", "-1", fmt.Sprintf("--before=%d", t.Unix()), shadowRef, "--", s.branchDir)
+	if err != nil {
+		return nil, err
+	}
+	if commit == "" {
+		return nil, fmt.Errorf("%w for branch %s before %s", ErrNoMetaAt, s.branch, t.Format("2006-01-02 15:04:05"))
+	}
+	synced, _, err := readBlob(s.ctx, commit, path.Join(s.branchDir, SyncStateFile))
+	if err != nil {
+		return nil, err
+	}
+	return &MetaPoint{Commit: commit, Kind: AtDate, SyncedTo: strings.TrimSpace(synced), Exact: true}, nil
+}
+
+// ReadAt returns the content of a file at a shadow branch commit.
+// The second return value reports whether the file existed.
+func (s *ShadowStore) ReadAt(commit, filename string) (string, bool, error) {
+	return readBlob(s.ctx, commit, path.Join(s.branchDir, filename))
+}
+
+// Diff returns a unified diff of the branch's META between two shadow
+// branch commits, limited to filename unless it is empty
+func (s *ShadowStore) Diff(from, to, filename string) (string, error) {
+	if !IsInitialized(s.ctx) {
+		return "", ErrNotInitialized
+	}
+	return runGit(s.ctx, "", "diff", "--relative="+s.branchDir+"/", from, to, "--", path.Join(s.branchDir, filename))
+}
@@ -0,0 +1,159 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// OpReserve marks commits that reserve or release item IDs
+const OpReserve = "reserve"
+
+// ItemIDFile returns the file that stores the next ID of an item type,
+// e.g. ".next_issue_id". Like .next_feed_id, lm owns the counters: IDs
+// are allocated under the META lock and handed to the skills, which never
+// choose IDs themselves.
+func ItemIDFile(typ items.Type) string {
+	return ".next_" + string(typ) + "_id"
+}
+
+// isItemIDFile reports whether a META path is the ID counter of an item type
+func isItemIDFile(p string) bool {
+	for _, typ := range items.Types {
+		if p == ItemIDFile(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// readItemCounter reads the counter of an item type, 0 if there is none
+func readItemCounter(s MetaStore, typ items.Type) (int, error) {
+	content, err := s.Read(ItemIDFile(typ))
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(content)
+	if err != nil || n < 1 {
+		return 0, corrupt(ItemIDFile(typ), "invalid %s ID %q", typ, content)
+	}
+	return n, nil
+}
+
+// filedItemNumbers returns the ID numbers of the items of a type filed in
+// the store. They are taken from the file names, so allocating IDs never
+// reads an item and a file that doesn't parse still keeps its ID.
+func filedItemNumbers(s MetaStore, typ items.Type) ([]int, error) {
+	files, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var numbers []int
+	for _, f := range files {
+		if t, ok := items.TypeOfPath(f); !ok || t != typ {
+			continue
+		}
+		if n := items.IDNumber(items.IDFromPath(f)); n > 0 {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
+
+// GetNextItemID returns the number of the next ID of an item type. A
+// counter behind the items already filed, as in META written before the
+// counters existed, is taken to be past them.
+func GetNextItemID(s MetaStore, typ items.Type) (int, error) {
+	next, err := readItemCounter(s, typ)
+	if err != nil {
+		return 0, err
+	}
+	next = max(next, 1)
+
+	filed, err := filedItemNumbers(s, typ)
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range filed {
+		if n >= next {
+			next = n + 1
+		}
+	}
+	return next, nil
+}
+
+// allocateItemIDs stages moving the counter of an item type past n IDs
+// and returns them
+func allocateItemIDs(s MetaStore, typ items.Type, n int) ([]string, error) {
+	next, err := GetNextItemID(s, typ)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, n)
+	for k := range ids {
+		ids[k] = items.FormatID(typ, next+k)
+	}
+	return ids, s.Write(ItemIDFile(typ), strconv.Itoa(next+n)+"\n")
+}
+
+// ReserveItemIDs allocates n IDs of an item type in one commit, for a
+// skill to file new items under. Call it under the META lock and give the
+// unused IDs back with ReleaseItemIDs once the skill is done.
+func ReserveItemIDs(s MetaStore, typ items.Type, n int) ([]string, error) {
+	if n < 1 {
+		return nil, nil
+	}
+	ids, err := allocateItemIDs(s, typ, n)
+	if err != nil {
+		s.Rollback()
+		return nil, err
+	}
+	trailers := &Trailers{Operation: OpReserve, Items: ids}
+	return ids, s.Commit(trailers.Message(fmt.Sprintf("Reserve %s to %s", ids[0], ids[len(ids)-1])))
+}
+
+// ReleaseItemIDs gives back the reserved IDs after the last one an item was
+// filed under, so an unused reservation leaves no gap. The counter only
+// moves back when no IDs were allocated after the reservation.
+func ReleaseItemIDs(s MetaStore, typ items.Type, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	first, last := items.IDNumber(ids[0]), items.IDNumber(ids[len(ids)-1])
+	counter, err := readItemCounter(s, typ)
+	if err != nil || counter != last+1 {
+		return err
+	}
+
+	filed, err := filedItemNumbers(s, typ)
+	if err != nil {
+		return err
+	}
+	next := first
+	for _, n := range filed {
+		if n >= next && n <= last {
+			next = n + 1
+		}
+	}
+	if next > last {
+		return nil
+	}
+
+	if err := s.Write(ItemIDFile(typ), strconv.Itoa(next)+"\n"); err != nil {
+		s.Rollback()
+		return err
+	}
+	released := ids[next-first:]
+	trailers := &Trailers{Operation: OpReserve, Items: released}
+	return s.Commit(trailers.Message(fmt.Sprintf("Release %s to %s", released[0], released[len(released)-1])))
+}
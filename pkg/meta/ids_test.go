@@ -0,0 +1,43 @@
+package meta
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+func TestItemIDsFollowFileNames(t *testing.T) {
+	s := NewMemStore(context.Background())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	// A file that doesn't parse still holds its ID
+	s.Write("Issues/issue-003-broken.md", "---\nid: issue-003\n")
+	if err := s.Commit("File a broken issue"); err != nil {
+		t.Fatal(err)
+	}
+	if next, err := GetNextItemID(s, items.TypeIssue); err != nil || next != 4 {
+		t.Fatalf("GetNextItemID() = %d, %v, want 4", next, err)
+	}
+
+	ids, err := ReserveItemIDs(s, items.TypeIssue, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"issue-004", "issue-005", "issue-006"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ReserveItemIDs() = %v, want %v", ids, want)
+	}
+	s.Write("Issues/issue-004-slow.md", "not even an item")
+	if err := s.Commit("File issue-004"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReleaseItemIDs(s, items.TypeIssue, ids); err != nil {
+		t.Fatal(err)
+	}
+	if next, err := GetNextItemID(s, items.TypeIssue); err != nil || next != 5 {
+		t.Errorf("GetNextItemID() after release = %d, %v, want 5", next, err)
+	}
+}
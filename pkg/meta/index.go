@@ -0,0 +1,335 @@
+package meta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// OpIndex marks commits that rebuild the item indexes
+const OpIndex = "index"
+
+// IndexFile returns the index of an item type, e.g. "Issues/issue-meta.jsonl".
+// It holds one IndexEntry per item as a JSON line, so listing items reads
+// one file instead of every item.
+func IndexFile(typ items.Type) string {
+	return path.Join(typ.Dir(), string(typ)+"-meta.jsonl")
+}
+
+// IndexEntry is the line of an item in the index of its type
+type IndexEntry struct {
+	ID       string    `json:"id"`
+	Status   string    `json:"status"`
+	Title    string    `json:"title"`
+	Priority string    `json:"priority,omitempty"`
+	Labels   []string  `json:"labels,omitempty"`
+	Updated  time.Time `json:"updated"`
+	Path     string    `json:"path"`
+	// Blocks and BlockedBy are the dependencies of a task
+	Blocks    []string `json:"blocks,omitempty"`
+	BlockedBy []string `json:"blocked_by,omitempty"`
+}
+
+// isIndexFile reports whether a META path is the index of an item type
+func isIndexFile(p string) bool {
+	for _, typ := range items.Types {
+		if p == IndexFile(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexEntry returns the index line of an item
+func indexEntry(item *items.Item) IndexEntry {
+	updated := item.Updated
+	if updated.IsZero() {
+		updated = item.Created
+	}
+	return IndexEntry{
+		ID:        item.ID,
+		Status:    item.Status,
+		Title:     item.Title(),
+		Priority:  item.Priority,
+		Labels:    item.Labels,
+		Updated:   updated,
+		Path:      item.Path,
+		Blocks:    item.Linked(items.LinkBlocks),
+		BlockedBy: item.Linked(items.LinkBlockedBy),
+	}
+}
+
+// ReadIndex returns the entries of an item type's index, ordered by path,
+// and false if the type has no index yet
+func ReadIndex(s MetaStore, typ items.Type) ([]IndexEntry, bool, error) {
+	content, err := s.Read(IndexFile(typ))
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	entries, err := parseIndex(IndexFile(typ), content)
+	return entries, true, err
+}
+
+// FindItemPath returns the path of the item of a type that ref names, by
+// its ID or file name, and false if there is none. It looks the item up in
+// the index and only scans the file names of the type when the type has
+// no index yet; item files are not read either way.
+func FindItemPath(s MetaStore, typ items.Type, ref string) (string, bool, error) {
+	ref = strings.TrimSuffix(ref, ".md")
+	matches := func(id, p string) bool {
+		p = strings.TrimSuffix(p, ".md")
+		return id == ref || p == ref || path.Base(p) == ref
+	}
+
+	entries, found, err := ReadIndex(s, typ)
+	if err != nil {
+		return "", false, err
+	}
+	if found {
+		for _, e := range entries {
+			if matches(e.ID, e.Path) {
+				return e.Path, true, nil
+			}
+		}
+		return "", false, nil
+	}
+
+	files, err := s.List()
+	if err != nil {
+		return "", false, err
+	}
+	for _, p := range files {
+		if t, ok := items.TypeOfPath(p); ok && t == typ && matches(items.IDFromPath(p), p) {
+			return p, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// formatIndex returns the content of an index with entries ordered by path
+func formatIndex(entries []IndexEntry) (string, error) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// parseIndex parses the content of an index
+func parseIndex(name, content string) ([]IndexEntry, error) {
+	var entries []IndexEntry
+	for n, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var e IndexEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, corrupt(name, "line %d: %v", n+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// mergeIndex merges two versions of an index, keeping the more recently
+// updated entry of an item both sides changed
+func mergeIndex(local, remote string) (string, error) {
+	byPath := make(map[string]IndexEntry)
+	for _, content := range []string{local, remote} {
+		entries, err := parseIndex("index", content)
+		if err != nil {
+			return "", err
+		}
+		for _, e := range entries {
+			if have, ok := byPath[e.Path]; !ok || e.Updated.After(have.Updated) {
+				byPath[e.Path] = e
+			}
+		}
+	}
+	entries := make([]IndexEntry, 0, len(byPath))
+	for _, e := range byPath {
+		entries = append(entries, e)
+	}
+	return formatIndex(entries)
+}
+
+// duplicateIndexIDs returns the IDs an index lists for more than one file,
+// in order
+func duplicateIndexIDs(content string) []string {
+	entries, err := parseIndex("index", content)
+	if err != nil {
+		return nil
+	}
+	paths := make(map[string]int)
+	for _, e := range entries {
+		paths[e.ID]++
+	}
+	var ids []string
+	for id, n := range paths {
+		if n > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// BuildIndexEntries returns the entries of an item type computed from its
+// files, ordered by path as ReadIndex returns them. It stands in for an
+// index that wasn't built yet, as in META written before indexes existed.
+// Files that don't parse are left out and reported in an items.FileErrors.
+func BuildIndexEntries(s MetaStore, typ items.Type) ([]IndexEntry, error) {
+	all, err := items.LoadAll(s, typ)
+	var bad items.FileErrors
+	if err != nil && !errors.As(err, &bad) {
+		return nil, err
+	}
+	entries := make([]IndexEntry, 0, len(all))
+	for _, item := range all {
+		entries = append(entries, indexEntry(item))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, err
+}
+
+// buildIndex returns the index of an item type computed from its files
+func buildIndex(s MetaStore, typ items.Type) (string, error) {
+	entries, err := BuildIndexEntries(s, typ)
+	if err != nil {
+		return "", err
+	}
+	return formatIndex(entries)
+}
+
+// saveItems stages the items and their lines in the indexes of their
+// types, so an item change and its index update land in the same commit
+func saveItems(s MetaStore, changed ...*items.Item) error {
+	byType := make(map[items.Type][]*items.Item)
+	var types []items.Type
+	for _, item := range changed {
+		if err := items.Save(s, item); err != nil {
+			return err
+		}
+		if byType[item.Type] == nil {
+			types = append(types, item.Type)
+		}
+		byType[item.Type] = append(byType[item.Type], item)
+	}
+
+	for _, typ := range types {
+		// Staged writes aren't readable yet: start from the committed
+		// index, or from the committed files when there is none
+		entries, found, err := ReadIndex(s, typ)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if entries, err = BuildIndexEntries(s, typ); err != nil {
+				return err
+			}
+		}
+
+		for _, item := range byType[typ] {
+			entry := indexEntry(item)
+			replaced := false
+			for n := range entries {
+				if entries[n].Path == entry.Path {
+					entries[n], replaced = entry, true
+				}
+			}
+			if !replaced {
+				entries = append(entries, entry)
+			}
+		}
+
+		content, err := formatIndex(entries)
+		if err != nil {
+			return err
+		}
+		if err := s.Write(IndexFile(typ), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StaleIndexes returns the item types whose index is missing or disagrees
+// with their item files, as after a skill edited items directly
+func StaleIndexes(s MetaStore) ([]items.Type, error) {
+	var stale []items.Type
+	for _, typ := range items.Types {
+		want, err := buildIndex(s, typ)
+		if err != nil {
+			return nil, err
+		}
+		have, err := s.Read(IndexFile(typ))
+		if errors.Is(err, ErrNotFound) {
+			// Nothing to index is fine without an index
+			if want == "" {
+				continue
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		if have != want {
+			stale = append(stale, typ)
+		}
+	}
+	return stale, nil
+}
+
+// RebuildIndexes regenerates the indexes of the given item types from
+// their item files in one commit. Indexes that already agree with the
+// files are left alone; it returns the types whose index was rewritten.
+func RebuildIndexes(s MetaStore, types ...items.Type) ([]items.Type, error) {
+	stale, err := StaleIndexes(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var rebuilt []items.Type
+	for _, typ := range stale {
+		wanted := len(types) == 0
+		for _, t := range types {
+			wanted = wanted || t == typ
+		}
+		if !wanted {
+			continue
+		}
+		content, err := buildIndex(s, typ)
+		if err != nil {
+			s.Rollback()
+			return nil, err
+		}
+		if err := s.Write(IndexFile(typ), content); err != nil {
+			s.Rollback()
+			return nil, err
+		}
+		rebuilt = append(rebuilt, typ)
+	}
+	if len(rebuilt) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, len(rebuilt))
+	for n, typ := range rebuilt {
+		names[n] = IndexFile(typ)
+	}
+	trailers := &Trailers{Operation: OpIndex}
+	return rebuilt, s.Commit(trailers.Message(fmt.Sprintf("Rebuild %s", strings.Join(names, ", "))))
+}
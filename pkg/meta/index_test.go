@@ -0,0 +1,55 @@
+package meta
+
+import (
+	"context"
+	"testing"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+func TestFindItemPath(t *testing.T) {
+	s := NewMemStore(context.Background())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	// Item files are never parsed, so a broken one is found by its name
+	s.Write("Issues/issue-001-slow.md", "---\nid: issue-001\n")
+	s.Write("Issues/issue-002-flaky.md", "---\nid: issue-002\n")
+	if err := s.Commit("File issues"); err != nil {
+		t.Fatal(err)
+	}
+
+	find := func(typ items.Type, ref string) string {
+		t.Helper()
+		p, found, err := FindItemPath(s, typ, ref)
+		if err != nil {
+			t.Fatalf("FindItemPath(%s) error = %v", ref, err)
+		}
+		if !found {
+			return ""
+		}
+		return p
+	}
+
+	// Without an index the file names are scanned
+	for _, ref := range []string{"issue-001", "issue-001-slow", "issue-001-slow.md", "Issues/issue-001-slow.md"} {
+		if got := find(items.TypeIssue, ref); got != "Issues/issue-001-slow.md" {
+			t.Errorf("FindItemPath(%s) without index = %q, want the issue file", ref, got)
+		}
+	}
+	if got := find(items.TypeProposal, "issue-001"); got != "" {
+		t.Errorf("FindItemPath(proposal, issue-001) = %q, want not found", got)
+	}
+
+	// With an index only the index is consulted
+	s.Write(IndexFile(items.TypeIssue), `{"id":"issue-002","status":"open","title":"Flaky","updated":"2025-04-01T00:00:00Z","path":"Issues/issue-002-flaky.md"}`+"\n")
+	if err := s.Commit("Index issues"); err != nil {
+		t.Fatal(err)
+	}
+	if got := find(items.TypeIssue, "issue-002"); got != "Issues/issue-002-flaky.md" {
+		t.Errorf("FindItemPath(issue-002) = %q, want the indexed path", got)
+	}
+	if got := find(items.TypeIssue, "issue-001"); got != "" {
+		t.Errorf("FindItemPath(issue-001) = %q, want not found when the index lacks it", got)
+	}
+}
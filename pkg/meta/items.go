@@ -0,0 +1,294 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// MigrateItems rewrites the items still in the legacy bold-field format
+// with frontmatter, in one commit, and returns them
+func MigrateItems(s MetaStore) ([]*items.Item, error) {
+	migrated, err := items.MigrateLegacy(s)
+	if err != nil {
+		s.Rollback()
+		return nil, err
+	}
+	if len(migrated) == 0 {
+		return nil, nil
+	}
+	// Legacy items read the same before and after, so the indexes can be
+	// built from the committed files
+	for _, typ := range items.Types {
+		content, err := buildIndex(s, typ)
+		if err == nil && content != "" {
+			err = s.Write(IndexFile(typ), content)
+		}
+		if err != nil {
+			s.Rollback()
+			return nil, err
+		}
+	}
+
+	trailers := &Trailers{Operation: OpMigrate}
+	for _, item := range migrated {
+		trailers.Items = append(trailers.Items, item.ID)
+	}
+	return migrated, s.Commit(trailers.Message(fmt.Sprintf("Migrate %d item(s) to frontmatter", len(migrated))))
+}
+
+// legacyProposalDir is where older versions of the propose skill filed
+// proposals. Only the migration to Proposals/ reads it.
+const legacyProposalDir = "Suggestions"
+
+// migratedPath returns where the migration moves a file of a legacy
+// directory, and false for files that stay where they are
+func migratedPath(rel string) (string, bool) {
+	name, ok := strings.CutPrefix(rel, legacyProposalDir+"/")
+	if !ok {
+		return "", false
+	}
+	return path.Join(items.TypeProposal.Dir(), name), true
+}
+
+// HasLegacyProposals reports whether proposals filed by older versions
+// still wait to be moved to Proposals/
+func HasLegacyProposals(s MetaStore) bool {
+	files, err := s.List()
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		if _, ok := migratedPath(f); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MoveLegacyProposals moves the files older versions filed in Suggestions/
+// to Proposals/ and adds the moved proposals to the index, in one commit.
+// The files keep their content. It returns the paths the files moved to.
+func MoveLegacyProposals(s MetaStore) ([]string, error) {
+	files, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := BuildIndexEntries(s, items.TypeProposal)
+	var bad items.FileErrors
+	if err != nil && !errors.As(err, &bad) {
+		return nil, err
+	}
+	var moved []string
+	for _, f := range files {
+		to, ok := migratedPath(f)
+		if !ok {
+			continue
+		}
+		content, err := s.Read(f)
+		if err != nil {
+			s.Rollback()
+			return nil, err
+		}
+		s.Delete(f)
+		s.Write(to, content)
+		moved = append(moved, to)
+		// Files that don't parse move all the same and are reported
+		// when items are loaded
+		if item, err := items.ParseFile(to, content); err == nil {
+			entries = append(entries, indexEntry(item))
+		}
+	}
+	if len(moved) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	index, err := formatIndex(entries)
+	if err != nil {
+		s.Rollback()
+		return nil, err
+	}
+	if index != "" {
+		s.Write(IndexFile(items.TypeProposal), index)
+	}
+
+	trailers := &Trailers{Operation: OpMigrate}
+	return moved, s.Commit(trailers.Message(fmt.Sprintf("Move %d file(s) from %s/ to %s/", len(moved), legacyProposalDir, items.TypeProposal.Dir())))
+}
+
+// SetItemStatus moves an item to a new status in one commit. The change is
+// validated against the item type's lifecycle and recorded in the item's
+// history with actor and reason.
+func SetItemStatus(s MetaStore, item *items.Item, status, actor, reason string) error {
+	from := item.Status
+	if err := item.SetStatus(status, actor, reason, time.Now().UTC().Truncate(time.Second)); err != nil {
+		return err
+	}
+	if err := saveItems(s, item); err != nil {
+		s.Rollback()
+		return err
+	}
+
+	trailers := &Trailers{Operation: OpStatus, Items: []string{item.ID}}
+	return s.Commit(trailers.Message(fmt.Sprintf("Move %s from %s to %s", item.ID, from, status)))
+}
+
+// Link relations between a task and the item it was created from
+const (
+	LinkTask = "task"
+	LinkFrom = "from"
+)
+
+// Operations on items
+const (
+	// OpStatus marks commits that move an item to another status
+	OpStatus = "status"
+	// OpTask marks commits that create a task from an approved item
+	OpTask = "task"
+	// OpEdit marks commits that change an item's priority or labels
+	OpEdit = "edit"
+	// OpDepend marks commits that add or remove a dependency between tasks
+	OpDepend = "depend"
+	// OpComment marks commits that add a comment to an item's discussion
+	OpComment = "comment"
+)
+
+// EditItem saves changes to an item's fields other than its status, such
+// as its priority or labels, in one commit. change describes them for the
+// commit subject, e.g. "priority P1".
+func EditItem(s MetaStore, item *items.Item, change string) error {
+	item.Updated = time.Now().UTC().Truncate(time.Second)
+	if err := saveItems(s, item); err != nil {
+		s.Rollback()
+		return err
+	}
+
+	trailers := &Trailers{Operation: OpEdit, Items: []string{item.ID}}
+	return s.Commit(trailers.Message(fmt.Sprintf("Edit %s: %s", item.ID, change)))
+}
+
+// Comment appends a comment by author to the item's discussion, in one
+// commit
+func Comment(s MetaStore, item *items.Item, author, text string) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	item.AddComment(author, text, now)
+	item.Updated = now
+	if err := saveItems(s, item); err != nil {
+		s.Rollback()
+		return err
+	}
+
+	trailers := &Trailers{Operation: OpComment, Items: []string{item.ID}}
+	return s.Commit(trailers.Message("Comment on " + item.ID))
+}
+
+// stageTask stages a task for an approved issue or proposal. The task takes
+// over the source's title, priority, labels and citations, its acceptance
+// criteria and a link back to it. The source moves to task-created and
+// links to the task.
+func stageTask(s MetaStore, source *items.Item, actor, reason string, now time.Time) (*items.Item, error) {
+	ids, err := allocateItemIDs(s, items.TypeTask, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	task := items.New(items.TypeTask, ids[0], source.Title(), now)
+	task.Priority = source.Priority
+	task.Labels = append([]string(nil), source.Labels...)
+	task.Sources = append([]items.Source(nil), source.Sources...)
+	task.LinkTo(LinkFrom, source.ID)
+	task.Body = taskBody(source)
+
+	if err := source.SetStatus(items.StatusTaskCreated, actor, reason, now); err != nil {
+		return nil, err
+	}
+	source.LinkTo(LinkTask, task.ID)
+
+	if err := saveItems(s, task, source); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// taskBody writes the body of a task created from source. Acceptance
+// criteria come from the source's own section when it has one, else from
+// what the source says should be done. The source's discussion comes along,
+// as it often says how the work should be done.
+func taskBody(source *items.Item) string {
+	criteria := source.Section("Acceptance Criteria")
+	if criteria == "" {
+		for _, section := range []string{"Recommendation", "What", "How"} {
+			if text := source.Section(section); text != "" {
+				criteria = text
+				break
+			}
+		}
+	}
+	if criteria == "" {
+		criteria = "- " + source.Title()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Task: %s\n\n", source.Title())
+	fmt.Fprintf(&b, "Created from %s %s (`%s`).\n\n", source.Type, source.ID, source.Path)
+	fmt.Fprintf(&b, "## Acceptance Criteria\n\n%s\n", criteria)
+	if problem := source.Section("Problem"); problem != "" {
+		fmt.Fprintf(&b, "\n## Context\n\n%s\n", problem)
+	}
+	if discussion := source.Section(items.DiscussionSection); discussion != "" {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", items.DiscussionSection, discussion)
+	}
+	return b.String()
+}
+
+// AddDependency records that blocker must be done before blocked, as a
+// blocks link on the blocker and a blocked-by link on the blocked task, in
+// one commit. A dependency that would close a cycle is refused with
+// items.ErrDependencyCycle.
+func AddDependency(s MetaStore, blocked, blocker *items.Item) error {
+	if blocked.Type != items.TypeTask || blocker.Type != items.TypeTask {
+		return fmt.Errorf("%w: %s %s depending on %s %s", items.ErrNotTask, blocked.Type, blocked.ID, blocker.Type, blocker.ID)
+	}
+
+	tasks, err := items.LoadAll(s, items.TypeTask)
+	if err != nil {
+		return err
+	}
+	deps := items.TaskDeps(tasks)
+	deps.Block(blocked.ID, blocker.ID)
+	if cycle := deps.Cycle(); cycle != nil {
+		return fmt.Errorf("%w: %s", items.ErrDependencyCycle, strings.Join(cycle, " -> ")+" (each waits for the next)")
+	}
+
+	blocker.LinkTo(items.LinkBlocks, blocked.ID)
+	blocked.LinkTo(items.LinkBlockedBy, blocker.ID)
+	return commitDependency(s, blocked, blocker, fmt.Sprintf("Make %s block %s", blocker.ID, blocked.ID))
+}
+
+// RemoveDependency removes the links that make blocked wait for blocker,
+// in one commit
+func RemoveDependency(s MetaStore, blocked, blocker *items.Item) error {
+	blocker.Unlink(items.LinkBlocks, blocked.ID)
+	blocked.Unlink(items.LinkBlockedBy, blocker.ID)
+	return commitDependency(s, blocked, blocker, fmt.Sprintf("Stop %s blocking %s", blocker.ID, blocked.ID))
+}
+
+// commitDependency saves both ends of a dependency change in one commit
+func commitDependency(s MetaStore, blocked, blocker *items.Item, subject string) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	blocked.Updated, blocker.Updated = now, now
+	if err := saveItems(s, blocked, blocker); err != nil {
+		s.Rollback()
+		return err
+	}
+
+	trailers := &Trailers{Operation: OpDepend, Items: []string{blocked.ID, blocker.ID}}
+	return s.Commit(trailers.Message(subject))
+}
@@ -0,0 +1,43 @@
+package meta
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+func TestMoveLegacyProposals(t *testing.T) {
+	s := NewMemStore(context.Background())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	s.Write("Suggestions/suggest-001-cache.md", "# Suggestion: Add a cache\n\n**Status**: Open\n")
+	s.Write("Proposals/proposal-002-help.md", "---\nid: proposal-002\ntype: proposal\nstatus: open\n---\n# Proposal: Add help\n")
+	if err := s.Commit("Add proposals"); err != nil {
+		t.Fatal(err)
+	}
+	if !HasLegacyProposals(s) {
+		t.Fatal("HasLegacyProposals() = false, want true")
+	}
+
+	moved, err := MoveLegacyProposals(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Proposals/suggest-001-cache.md"}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("moved = %v, want %v", moved, want)
+	}
+	if HasLegacyProposals(s) {
+		t.Error("HasLegacyProposals() = true after the move")
+	}
+
+	all, err := items.LoadAll(s, items.TypeProposal)
+	if err != nil || len(all) != 2 || all[0].ID != "proposal-002" || all[1].ID != "suggest-001" {
+		t.Errorf("LoadAll() = %+v, %v, want both proposals", all, err)
+	}
+	if stale, err := StaleIndexes(s); err != nil || len(stale) != 0 {
+		t.Errorf("StaleIndexes() = %v, %v, want none", stale, err)
+	}
+}
@@ -0,0 +1,329 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultLockTimeout is how long AcquireMetaLock waits by default
+	DefaultLockTimeout = 30 * time.Second
+	// LockTimeoutConfigKey is the git config key overriding the lock timeout
+	LockTimeoutConfigKey = "laddermoon.lockTimeout"
+	// LockTimeoutEnv is the environment variable overriding the lock timeout
+	LockTimeoutEnv = "LM_LOCK_TIMEOUT"
+)
+
+var (
+	ErrLockHeld     = errors.New("META lock is held by another process")
+	ErrLockHolderUp = errors.New("META lock holder is still running")
+)
+
+// LockHolder describes the process holding the META lock
+type LockHolder struct {
+	PID      int
+	Hostname string
+	Command  string
+	Started  time.Time
+}
+
+// String renders the holder in the format stored in the lock file
+func (h *LockHolder) String() string {
+	return fmt.Sprintf("pid: %d\nhostname: %s\ncommand: %s\nstarted: %s\n",
+		h.PID, h.Hostname, h.Command, h.Started.Format(time.RFC3339))
+}
+
+// Describe returns a one-line human readable description of the holder
+func (h *LockHolder) Describe() string {
+	return fmt.Sprintf("PID %d on %s running '%s' since %s",
+		h.PID, h.Hostname, h.Command, h.Started.Format("2006-01-02 15:04:05"))
+}
+
+// IsLocal reports whether the holder runs on this host
+func (h *LockHolder) IsLocal() bool {
+	hostname, _ := os.Hostname()
+	return h.Hostname == hostname
+}
+
+// Alive reports whether the holder process still exists. Holders on
+// other hosts can't be checked and are assumed to be alive.
+func (h *LockHolder) Alive() bool {
+	if !h.IsLocal() {
+		return true
+	}
+	if h.PID <= 0 {
+		return false
+	}
+	err := syscall.Kill(h.PID, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// parseLockHolder parses the content of the lock file
+func parseLockHolder(content string) *LockHolder {
+	h := &LockHolder{}
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "pid":
+			h.PID, _ = strconv.Atoi(value)
+		case "hostname":
+			h.Hostname = value
+		case "command":
+			h.Command = value
+		case "started":
+			h.Started, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+	if h.PID == 0 {
+		return nil
+	}
+	return h
+}
+
+// LockHeldError is returned when the META lock could not be acquired in time
+type LockHeldError struct {
+	Holder *LockHolder
+	Waited time.Duration
+	Orphan bool
+}
+
+func (e *LockHeldError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("timeout after %s waiting for META lock", e.Waited)
+	}
+	if e.Orphan {
+		return fmt.Sprintf("META lock is held although its holder (%s) has exited; run 'lm unlock --force' to clear it", e.Holder.Describe())
+	}
+	return fmt.Sprintf("timeout after %s waiting for META lock held by %s", e.Waited, e.Holder.Describe())
+}
+
+func (e *LockHeldError) Unwrap() error {
+	return ErrLockHeld
+}
+
+// MetaLock represents a lock file for serializing META operations
+type MetaLock struct {
+	file *os.File
+	path string
+	// Recovered is the holder recorded by a previous process that exited
+	// without releasing the lock, if any
+	Recovered *LockHolder
+}
+
+// lockPath returns the path of the lock file in the project root
+func lockPath(ctx context.Context) (string, error) {
+	gitRoot, err := GetGitRoot(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitRoot, LockFile), nil
+}
+
+// LockTimeout returns the configured lock timeout from the LM_LOCK_TIMEOUT
+// environment variable or git config laddermoon.lockTimeout
+func LockTimeout(ctx context.Context) time.Duration {
+	value := os.Getenv(LockTimeoutEnv)
+	if value == "" {
+		output, err := exec.CommandContext(ctx, "git", "config", "--get", LockTimeoutConfigKey).Output()
+		if err == nil {
+			value = strings.TrimSpace(string(output))
+		}
+	}
+	if value == "" {
+		return DefaultLockTimeout
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return DefaultLockTimeout
+}
+
+// ReadLockHolder returns the holder recorded in the lock file, or nil
+func ReadLockHolder(ctx context.Context) (*LockHolder, error) {
+	path, err := lockPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseLockHolder(string(content)), nil
+}
+
+// AcquireMetaLock acquires an exclusive lock for META operations, waiting
+// up to the configured LockTimeout. The lock file is created in the project
+// root directory and records the holder while the lock is held.
+func AcquireMetaLock(ctx context.Context) (*MetaLock, error) {
+	return AcquireMetaLockTimeout(ctx, LockTimeout(ctx), nil)
+}
+
+// AcquireMetaLockTimeout acquires the META lock, waiting up to timeout or
+// until ctx is done. onWait is called once with the current holder when
+// the lock is busy.
+func AcquireMetaLockTimeout(ctx context.Context, timeout time.Duration, onWait func(*LockHolder)) (*MetaLock, error) {
+	path, err := lockPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	notified := false
+
+	for {
+		lock, err := tryLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if lock != nil {
+			return lock, nil
+		}
+
+		holder, _ := ReadLockHolder(ctx)
+		if holder != nil && !notified && onWait != nil {
+			onWait(holder)
+			notified = true
+		}
+
+		// The flock is still held although the recorded holder is gone:
+		// a descendant process inherited it, waiting won't help. Give a
+		// fresh holder a moment to replace the record of a crashed one first.
+		if holder != nil && !holder.Alive() && time.Since(start) > time.Second {
+			return nil, &LockHeldError{Holder: holder, Waited: time.Since(start), Orphan: true}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, &LockHeldError{Holder: holder, Waited: timeout}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// tryLock makes one attempt to take the lock. It returns nil without an
+// error when another process holds it.
+func tryLock(path string) (*MetaLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	// The file may have been removed or replaced between open and flock by
+	// a releasing holder or 'lm unlock'; the lock is only ours if the path
+	// still refers to the file we locked
+	if !sameFile(file, path) {
+		file.Close()
+		return nil, nil
+	}
+
+	content := make([]byte, 4096)
+	n, _ := file.ReadAt(content, 0)
+	lock := &MetaLock{file: file, path: path, Recovered: parseLockHolder(string(content[:n]))}
+
+	hostname, _ := os.Hostname()
+	holder := &LockHolder{
+		PID:      os.Getpid(),
+		Hostname: hostname,
+		Command:  strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "),
+		Started:  time.Now(),
+	}
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(holder.String()), 0)
+	}
+	return lock, nil
+}
+
+// sameFile reports whether path refers to the open file
+func sameFile(file *os.File, path string) bool {
+	openInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(openInfo, pathInfo)
+}
+
+// Release releases the lock. The lock file is removed only while it is
+// still the file this lock holds, so a waiting process is never left
+// holding a lock on a file another process already replaced.
+func (l *MetaLock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+	if sameFile(l.file, l.path) {
+		os.Remove(l.path)
+	}
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+	l.file = nil
+	return nil
+}
+
+// Unlock clears the META lock file. A free lock with a stale holder record
+// is always cleared. A held lock is only broken with force, and never while
+// its holder is a running process on this host. It returns the holder that
+// was recorded in the lock file, if any.
+func Unlock(ctx context.Context, force bool) (*LockHolder, error) {
+	path, err := lockPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := tryLock(path)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		holder := lock.Recovered
+		lock.Release()
+		return holder, nil
+	}
+
+	holder, _ := ReadLockHolder(ctx)
+	if !force {
+		return holder, ErrLockHeld
+	}
+	if holder != nil && holder.IsLocal() && holder.Alive() {
+		return holder, ErrLockHolderUp
+	}
+
+	// Unlinking is safe: the orphaned flock stays on the removed file and
+	// new processes lock a fresh one
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return holder, fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return holder, nil
+}
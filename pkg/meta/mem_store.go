@@ -0,0 +1,145 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+// memRevision is a revision of a MemStore with the files it touched
+type memRevision struct {
+	Revision
+	files map[string]bool
+}
+
+// MemStore keeps META in memory. It is meant for tests and never
+// touches git or the file system.
+type MemStore struct {
+	ctx       context.Context
+	files     map[string]string
+	ops       []txOp
+	revisions []memRevision
+}
+
+// NewMemStore returns an empty in-memory store bound to ctx
+func NewMemStore(ctx context.Context) *MemStore {
+	return &MemStore{ctx: ctx, files: make(map[string]string)}
+}
+
+// Location describes where the store keeps its files
+func (s *MemStore) Location() string {
+	return "memory"
+}
+
+// Initialized reports whether META.md exists
+func (s *MemStore) Initialized() bool {
+	_, ok := s.files[MetaFileName]
+	return ok
+}
+
+// Init creates META.md and the item directories
+func (s *MemStore) Init() error {
+	s.Write(MetaFileName, "")
+	for _, dir := range []string{"Questions", "Issues", "Proposals"} {
+		s.Write(path.Join(dir, ".gitkeep"), "")
+	}
+	return s.Commit((&Trailers{Operation: OpInit}).Message("Initialize LadderMoon META"))
+}
+
+// Read returns the content of a file, or ErrNotFound if it doesn't exist
+func (s *MemStore) Read(filename string) (string, error) {
+	content, ok := s.files[filename]
+	if !ok {
+		return "", notFound(filename)
+	}
+	return content, nil
+}
+
+// List returns the paths of all files
+func (s *MemStore) List() ([]string, error) {
+	result := make([]string, 0, len(s.files))
+	for name := range s.files {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Write stages overwriting a file with content
+func (s *MemStore) Write(filename, content string) error {
+	s.ops = append(s.ops, txOp{kind: txWrite, filename: filename, content: content})
+	return nil
+}
+
+// Append stages appending content to a file
+func (s *MemStore) Append(filename, content string) error {
+	s.ops = append(s.ops, txOp{kind: txAppend, filename: filename, content: content})
+	return nil
+}
+
+// Delete stages removing a file
+func (s *MemStore) Delete(filename string) error {
+	s.ops = append(s.ops, txOp{kind: txDelete, filename: filename})
+	return nil
+}
+
+// History returns the revisions that changed a file, newest first
+func (s *MemStore) History(filename string) ([]Revision, error) {
+	var result []Revision
+	for i := len(s.revisions) - 1; i >= 0; i-- {
+		rev := s.revisions[i]
+		if filename == "" || rev.files[filename] {
+			result = append(result, rev.Revision)
+		}
+	}
+	return result, nil
+}
+
+// Rollback discards all staged changes
+func (s *MemStore) Rollback() {
+	s.ops = nil
+}
+
+// Commit applies all staged changes and records them as one revision
+func (s *MemStore) Commit(message string) error {
+	ops := s.ops
+	s.ops = nil
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	changes, err := applyOps(ops, func(filename string) (string, error) {
+		return s.files[filename], nil
+	})
+	if err != nil {
+		return err
+	}
+
+	touched := make(map[string]bool, len(changes))
+	for filename, content := range changes {
+		touched[filename] = true
+		if content == nil {
+			delete(s.files, filename)
+			continue
+		}
+		s.files[filename] = *content
+	}
+
+	subject, trailers := ParseCommitMessage(message)
+	s.revisions = append(s.revisions, memRevision{
+		Revision: Revision{
+			ID:       fmt.Sprintf("mem-%d", len(s.revisions)+1),
+			Time:     time.Now(),
+			Message:  subject,
+			Body:     splitBody(message),
+			Trailers: trailers,
+		},
+		files: touched,
+	})
+	return nil
+}
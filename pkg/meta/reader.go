@@ -0,0 +1,275 @@
+package meta
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BatchReader reads git objects through one long-lived
+// `git cat-file --batch` process instead of starting git per read.
+// Blob and tree contents are cached by object ID, which never changes
+// meaning. Refs are resolved by the process on every request, so a reader
+// observes commits made while it is open. Files are looked up through
+// their trees, so cat-file is only asked for refs and object IDs, never for
+// paths, which may contain the spaces its replies are split on.
+type BatchReader struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	blobs  map[string]string
+	trees  map[string]string
+}
+
+// OpenBatchReader starts a cat-file process for the current repository,
+// which is killed when ctx is done
+func OpenBatchReader(ctx context.Context) (*BatchReader, error) {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git cat-file: %w", err)
+	}
+	return &BatchReader{
+		ctx:    ctx,
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReaderSize(stdout, 64*1024),
+		blobs:  make(map[string]string),
+		trees:  make(map[string]string),
+	}, nil
+}
+
+// object asks cat-file for an object by ID or ref. It returns the object ID, its type and content; missing objects
+// return an empty ID without an error.
+func (r *BatchReader) object(name string) (string, string, string, error) {
+	if r.cmd == nil {
+		return "", "", "", fmt.Errorf("git cat-file reader is closed")
+	}
+	if strings.ContainsAny(name, "\n") {
+		return "", "", "", nil
+	}
+	if _, err := io.WriteString(r.stdin, name+"\n"); err != nil {
+		return "", "", "", fmt.Errorf("git cat-file failed: %w", err)
+	}
+
+	header, err := r.stdout.ReadString('\n')
+	if err != nil {
+		return "", "", "", fmt.Errorf("git cat-file failed: %w", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		// "<name> missing" or "<name> ambiguous"
+		return "", "", "", nil
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", "", "", fmt.Errorf("git cat-file: malformed header %q", strings.TrimSpace(header))
+	}
+
+	// Content is followed by a newline
+	buf := make([]byte, size+1)
+	if _, err := io.ReadFull(r.stdout, buf); err != nil {
+		return "", "", "", fmt.Errorf("git cat-file failed: %w", err)
+	}
+	return fields[0], fields[1], string(buf[:size]), nil
+}
+
+// Resolve returns the object ID a name such as a ref points to,
+// or an empty string if it doesn't exist
+func (r *BatchReader) Resolve(name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, _, _, err := r.object(name)
+	return id, err
+}
+
+// Blob returns the content of the blob with the given object ID.
+// The second return value reports whether the blob exists.
+func (r *BatchReader) Blob(id string) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cached(r.blobs, id, "blob")
+}
+
+// cached returns the content of an object of type typ by ID from cache,
+// reading and caching it on a miss
+func (r *BatchReader) cached(cache map[string]string, id, typ string) (string, bool, error) {
+	if content, ok := cache[id]; ok {
+		return content, true, nil
+	}
+	found, foundType, content, err := r.object(id)
+	if err != nil || found == "" || foundType != typ {
+		return "", false, err
+	}
+	cache[found] = content
+	return content, true, nil
+}
+
+// ReadFile returns the content of a file at a path of a commit or tree.
+// The second return value reports whether the file exists.
+func (r *BatchReader) ReadFile(commit, path string) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, typ, content, err := r.object(commit)
+	if err != nil || id == "" {
+		return "", false, err
+	}
+	if typ == "commit" {
+		// The tree is the first header line of a commit
+		line, _, _ := strings.Cut(content, "\n")
+		tree, ok := strings.CutPrefix(line, "tree ")
+		if !ok {
+			return "", false, fmt.Errorf("git cat-file: commit %s has no tree", id)
+		}
+		id, typ = tree, "tree"
+	}
+
+	for _, name := range strings.Split(path, "/") {
+		if typ != "tree" {
+			return "", false, nil
+		}
+		tree, ok, err := r.cached(r.trees, id, "tree")
+		if err != nil || !ok {
+			return "", false, err
+		}
+		id, typ = findTreeEntry(tree, name, len(id)/2)
+		if id == "" {
+			return "", false, nil
+		}
+	}
+	if typ != "blob" {
+		return "", false, nil
+	}
+	return r.cached(r.blobs, id, "blob")
+}
+
+// findTreeEntry returns the object ID and type of an entry of a raw tree
+// object, whose entries are "<mode> <name>\x00" followed by the binary
+// object ID of hashSize bytes. It returns an empty ID if there is no such
+// entry.
+func findTreeEntry(tree, name string, hashSize int) (string, string) {
+	for len(tree) > 0 {
+		header, rest, ok := strings.Cut(tree, "\x00")
+		if !ok || len(rest) < hashSize {
+			return "", ""
+		}
+		mode, entry, _ := strings.Cut(header, " ")
+		if entry == name {
+			typ := "blob"
+			switch mode {
+			case "40000":
+				typ = "tree"
+			case "160000":
+				typ = "commit"
+			}
+			return hex.EncodeToString([]byte(rest[:hashSize])), typ
+		}
+		tree = rest[hashSize:]
+	}
+	return "", ""
+}
+
+// Close stops the cat-file process
+func (r *BatchReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cmd == nil {
+		return nil
+	}
+	r.stdin.Close()
+	err := r.cmd.Wait()
+	r.cmd = nil
+	return err
+}
+
+var (
+	sharedReaderMu sync.Mutex
+	sharedReader   *BatchReader
+)
+
+// reader returns the reader shared by the package, starting it under ctx
+// on first use, or again once the context it was started under is done
+func reader(ctx context.Context) (*BatchReader, error) {
+	sharedReaderMu.Lock()
+	defer sharedReaderMu.Unlock()
+
+	if sharedReader != nil && sharedReader.ctx.Err() != nil {
+		sharedReader.Close()
+		sharedReader = nil
+	}
+	if sharedReader == nil {
+		r, err := OpenBatchReader(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sharedReader = r
+	}
+	return sharedReader, nil
+}
+
+// CloseReader stops the cat-file process shared by the package, if any
+func CloseReader() error {
+	sharedReaderMu.Lock()
+	defer sharedReaderMu.Unlock()
+
+	if sharedReader == nil {
+		return nil
+	}
+	err := sharedReader.Close()
+	sharedReader = nil
+	return err
+}
+
+// treeSnapshot is the file listing of a branch directory at one commit
+type treeSnapshot struct {
+	commit string
+	// blobs maps paths relative to the branch directory to blob IDs
+	blobs map[string]string
+	// exists reports whether the branch directory exists at the commit
+	exists bool
+}
+
+// snapshotDir lists a branch directory at a commit with a single ls-tree
+func snapshotDir(ctx context.Context, commit, dir string) (*treeSnapshot, error) {
+	snap := &treeSnapshot{commit: commit, blobs: make(map[string]string)}
+	if commit == "" {
+		return snap, nil
+	}
+
+	output, err := runGit(ctx, "", "ls-tree", "-r", "-z", commit, "--", dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range strings.Split(output, "\x00") {
+		info, p, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		if rel, ok := strings.CutPrefix(p, dir+"/"); ok {
+			snap.blobs[rel] = fields[2]
+			snap.exists = true
+		}
+	}
+	return snap, nil
+}
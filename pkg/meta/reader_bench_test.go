@@ -0,0 +1,145 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+)
+
+// benchItems is the number of items in the benchmark META directory
+const benchItems = 1000
+
+// setupTestRepo creates a repository on branch main with an initialized
+// META, makes it the working directory and returns the branch's store
+func setupTestRepo(tb testing.TB) *ShadowStore {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		CloseReader()
+		os.Chdir(wd)
+	})
+
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+		{"commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			tb.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	store, err := NewShadowStore(context.Background())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := store.Init(); err != nil {
+		tb.Fatal(err)
+	}
+	return store
+}
+
+// setupBenchRepo creates a repository whose META directory for branch
+// main holds benchItems issues and makes it the working directory
+func setupBenchRepo(b *testing.B) *ShadowStore {
+	b.Helper()
+
+	ctx := context.Background()
+	store := setupTestRepo(b)
+	err := commitShadow(ctx, "Add benchmark issues", func(parent string) (map[string]*string, error) {
+		changes := make(map[string]*string, benchItems)
+		for i := 1; i <= benchItems; i++ {
+			content := fmt.Sprintf("# Issue: Benchmark issue %d\n\n**ID**: issue-%03d\n**Status**: Open\n\n## Problem\n\n%s\n",
+				i, i, strings.Repeat("Something is wrong. ", 20))
+			changes[path.Join(store.branchDir, fmt.Sprintf("Issues/issue-%03d-bench.md", i))] = &content
+		}
+		return changes, nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	CloseReader()
+	return store
+}
+
+// BenchmarkShadowStoreListAndRead lists the META directory and reads every
+// item like 'lm issues' does, starting from a fresh store and reader each run
+func BenchmarkShadowStoreListAndRead(b *testing.B) {
+	setupBenchRepo(b)
+	ctx := context.Background()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		store, err := NewShadowStore(ctx)
+		if err != nil {
+			b.Fatal(err)
+		}
+		files, err := store.List()
+		if err != nil {
+			b.Fatal(err)
+		}
+		read := 0
+		for _, f := range files {
+			if !strings.HasPrefix(f, "Issues/") {
+				continue
+			}
+			if _, err := store.Read(f); err != nil {
+				b.Fatalf("failed to read %s: %v", f, err)
+			}
+			read++
+		}
+		if read < benchItems {
+			b.Fatalf("read %d items, want %d", read, benchItems)
+		}
+		CloseReader()
+	}
+}
+
+// BenchmarkBatchReaderReadFile reads every item by path through one cat-file process
+func BenchmarkBatchReaderReadFile(b *testing.B) {
+	store := setupBenchRepo(b)
+	tip := resolveShadowTip(context.Background())
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r, err := OpenBatchReader(context.Background())
+		if err != nil {
+			b.Fatal(err)
+		}
+		for n := 1; n <= benchItems; n++ {
+			p := path.Join(store.branchDir, fmt.Sprintf("Issues/issue-%03d-bench.md", n))
+			if _, ok, err := r.ReadFile(tip, p); err != nil || !ok {
+				b.Fatalf("failed to read %s: %v", p, err)
+			}
+		}
+		r.Close()
+	}
+}
+
+// BenchmarkGitShowPerItem is the baseline of starting git once per item
+func BenchmarkGitShowPerItem(b *testing.B) {
+	store := setupBenchRepo(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for n := 1; n <= benchItems; n++ {
+			p := path.Join(store.branchDir, fmt.Sprintf("Issues/issue-%03d-bench.md", n))
+			if err := exec.Command("git", "show", BranchName+":"+p).Run(); err != nil {
+				b.Fatalf("failed to read %s: %v", p, err)
+			}
+		}
+	}
+}
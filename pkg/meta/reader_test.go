@@ -0,0 +1,49 @@
+package meta
+
+import (
+	"context"
+	"path"
+	"testing"
+)
+
+func TestBatchReaderReadFile(t *testing.T) {
+	store := setupTestRepo(t)
+	store.Write("Issues/issue-001 slow login.md", "# Issue: Slow login\n")
+	if err := store.Commit("Add an issue with spaces in its name"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := OpenBatchReader(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	tip := resolveShadowTip(ctx)
+	tests := []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{"Issues/issue-001 slow login.md", "# Issue: Slow login\n", true},
+		{"Issues/issue-002 missing file.md", "", false},
+		{"Issues", "", false},
+		{"Issues/issue-001 slow login.md/nested", "", false},
+	}
+	for _, tt := range tests {
+		content, found, err := r.ReadFile(tip, path.Join(store.branchDir, tt.path))
+		if err != nil || found != tt.found || content != tt.want {
+			t.Errorf("ReadFile(%q) = %q, %v, %v, want %q, %v", tt.path, content, found, err, tt.want, tt.found)
+		}
+	}
+	if content, err := store.Read("Issues/issue-001 slow login.md"); err != nil || content != "# Issue: Slow login\n" {
+		t.Errorf("Read() = %q, %v, want the issue", content, err)
+	}
+
+	// The process ends with its context
+	cancel()
+	if _, _, err := r.ReadFile(tip, path.Join(store.branchDir, MetaFileName)); err == nil {
+		t.Error("ReadFile() after cancel succeeded, want an error")
+	}
+}
@@ -0,0 +1,723 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// DefaultRemote is the remote used by Push and Pull when none is given
+const DefaultRemote = "origin"
+
+var (
+	ErrRemoteAhead          = errors.New("remote META has changes that are not merged locally, run 'lm meta pull' first")
+	ErrNoRemoteMeta         = errors.New("remote has no laddermoon-meta branch")
+	ErrMetaMovedDuringMerge = errors.New("META changed while merging, run the command again")
+)
+
+// feedHeaderRe matches the header line of a UserFeed.log entry
+var feedHeaderRe = regexp.MustCompile(`(?m)^=== Feed #(\d+) ===$`)
+
+// feedCitationRe matches a feed citation in META.md and item files
+var feedCitationRe = regexp.MustCompile(`Feed #(\d+)`)
+
+// Resolution describes how a conflicting path was merged
+type Resolution string
+
+const (
+	// ResolvedAppend merged an append-only log by concatenating both sides
+	ResolvedAppend Resolution = "append"
+	// ResolvedRenumber recomputed a counter after renumbering local feeds
+	ResolvedRenumber Resolution = "renumber"
+	// ResolvedText merged a file with a clean three-way textual merge
+	ResolvedText Resolution = "text"
+	// ResolvedNewer kept the sync state pointing at the newer code commit, or
+	// the higher item ID counter
+	ResolvedNewer Resolution = "newer"
+	// ResolvedUnion combined the entries of both sides of the branch registry
+	// or of an item index
+	ResolvedUnion Resolution = "union"
+	// NeedsReview marks a textual merge with conflicts the user must review
+	NeedsReview Resolution = "review"
+)
+
+// MergedFile is one path of the shadow branch changed by both sides
+type MergedFile struct {
+	Path       string
+	Resolution Resolution
+	// Local, Remote and Merged hold the contents for files needing review
+	Local  string
+	Remote string
+	Merged string
+}
+
+// Merge is the result of integrating the remote shadow branch
+type Merge struct {
+	Local  string
+	Remote string
+	Base   string
+	// FastForward is set when local had no changes of its own
+	FastForward bool
+	// UpToDate is set when remote had no changes of its own
+	UpToDate bool
+	// Renumbered maps local feed IDs to their new IDs, per branch directory
+	Renumbered map[string]map[int]int
+	// RenumberedItems maps the IDs of local items that collided with remote
+	// ones to their new IDs, per branch directory
+	RenumberedItems map[string]map[string]string
+	Files           []*MergedFile
+	changes         map[string]*string
+	// itemCounters holds the item ID counters following renumbered items,
+	// by path
+	itemCounters map[string]int
+	commits      commitReader
+}
+
+// commitReader reads the files of the commits a merge combines
+type commitReader interface {
+	// listBlobs returns all file paths of a commit with their object IDs
+	listBlobs(ctx context.Context, commit string) (map[string]string, error)
+	// readBlob returns the content of a file at a commit, and false if
+	// the commit has no such file
+	readBlob(ctx context.Context, commit, path string) (string, bool, error)
+}
+
+// gitCommits reads commits from the repository
+type gitCommits struct{}
+
+func (gitCommits) listBlobs(ctx context.Context, commit string) (map[string]string, error) {
+	return listBlobs(ctx, commit)
+}
+
+func (gitCommits) readBlob(ctx context.Context, commit, path string) (string, bool, error) {
+	return readBlob(ctx, commit, path)
+}
+
+// Conflicts returns the files whose textual merge needs review
+func (m *Merge) Conflicts() []*MergedFile {
+	var result []*MergedFile
+	for _, f := range m.Files {
+		if f.Resolution == NeedsReview {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Resolve sets the final content of a file that needed review
+func (m *Merge) Resolve(f *MergedFile, content string) {
+	f.Merged = content
+	f.Resolution = ResolvedText
+	m.changes[f.Path] = &content
+}
+
+// branchDirOf returns the branch directory a shadow branch path belongs to
+func branchDirOf(p string) string {
+	dir, _, _ := strings.Cut(p, "/")
+	return dir
+}
+
+// remoteRef returns the remote-tracking ref of the shadow branch
+func remoteRef(remote string) string {
+	return fmt.Sprintf("refs/remotes/%s/%s", remote, BranchName)
+}
+
+// FetchMeta fetches the shadow branch from remote and returns its tip
+func FetchMeta(ctx context.Context, remote string) (string, error) {
+	refspec := fmt.Sprintf("+%s:%s", shadowRef, remoteRef(remote))
+	if _, err := runGit(ctx, "", "ls-remote", "--exit-code", remote, shadowRef); err != nil {
+		return "", ErrNoRemoteMeta
+	}
+	if _, err := runGit(ctx, "", "fetch", "--quiet", remote, refspec); err != nil {
+		return "", fmt.Errorf("failed to fetch META: %w", err)
+	}
+	return runGitTrimmed(ctx, "", "rev-parse", remoteRef(remote))
+}
+
+// isAncestor reports whether commit a is an ancestor of commit b
+func isAncestor(ctx context.Context, a, b string) bool {
+	_, err := runGit(ctx, "", "merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// PushMeta pushes the local shadow branch to remote.
+// It refuses to push when the remote has changes that were not pulled yet.
+func PushMeta(ctx context.Context, remote string) error {
+	if !IsInitialized(ctx) {
+		return ErrNotInitialized
+	}
+
+	remoteTip, err := FetchMeta(ctx, remote)
+	if err != nil && !errors.Is(err, ErrNoRemoteMeta) {
+		return err
+	}
+	if remoteTip != "" && !isAncestor(ctx, remoteTip, shadowRef) {
+		return ErrRemoteAhead
+	}
+
+	if _, err := runGit(ctx, "", "push", "--quiet", remote, fmt.Sprintf("%s:%s", shadowRef, shadowRef)); err != nil {
+		return fmt.Errorf("failed to push META: %w", err)
+	}
+	return nil
+}
+
+// PrepareMerge fetches the remote shadow branch and merges it with the local
+// one in memory. Nothing is written until ApplyMerge is called.
+func PrepareMerge(ctx context.Context, remote string) (*Merge, error) {
+	remoteTip, err := FetchMeta(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	local := resolveShadowTip(ctx)
+	m := &Merge{Local: local, Remote: remoteTip, changes: make(map[string]*string), commits: gitCommits{}}
+
+	switch {
+	case local == "" || isAncestor(ctx, local, remoteTip):
+		m.FastForward = true
+		return m, nil
+	case isAncestor(ctx, remoteTip, local):
+		m.UpToDate = true
+		return m, nil
+	}
+
+	base, err := runGitTrimmed(ctx, "", "merge-base", local, remoteTip)
+	if err != nil {
+		base = "" // Unrelated histories, merge against an empty base
+	}
+	m.Base = base
+
+	if err := m.mergeTrees(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// listBlobs returns all blob paths of a commit with their object IDs
+func listBlobs(ctx context.Context, commit string) (map[string]string, error) {
+	blobs := make(map[string]string)
+	if commit == "" {
+		return blobs, nil
+	}
+	output, err := runGit(ctx, "", "ls-tree", "-r", "-z", commit)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range strings.Split(output, "\x00") {
+		info, name, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) == 3 && fields[1] == "blob" {
+			blobs[name] = fields[2]
+		}
+	}
+	return blobs, nil
+}
+
+// mergeTrees computes the merged content of every path changed on both sides
+func (m *Merge) mergeTrees(ctx context.Context) error {
+	baseBlobs, err := m.commits.listBlobs(ctx, m.Base)
+	if err != nil {
+		return err
+	}
+	localBlobs, err := m.commits.listBlobs(ctx, m.Local)
+	if err != nil {
+		return err
+	}
+	remoteBlobs, err := m.commits.listBlobs(ctx, m.Remote)
+	if err != nil {
+		return err
+	}
+
+	paths := make(map[string]bool)
+	for _, blobs := range []map[string]string{baseBlobs, localBlobs, remoteBlobs} {
+		for p := range blobs {
+			paths[p] = true
+		}
+	}
+
+	// Merge the feed logs first: renumbering decides the feed counters
+	// and the citations in the other files of the same branch directory
+	var conflicted []string
+	for p := range paths {
+		b, l, r := baseBlobs[p], localBlobs[p], remoteBlobs[p]
+		switch {
+		case l == r, r == b:
+			// Local already has the result
+		case l == b:
+			if r == "" {
+				m.changes[p] = nil
+			} else {
+				content, _, err := m.commits.readBlob(ctx, m.Remote, p)
+				if err != nil {
+					return err
+				}
+				m.changes[p] = &content
+			}
+		default:
+			conflicted = append(conflicted, p)
+		}
+	}
+	if err := m.renumberItems(ctx, baseBlobs, localBlobs, remoteBlobs); err != nil {
+		return err
+	}
+
+	sort.Slice(conflicted, func(i, j int) bool {
+		iLog, jLog := path.Base(conflicted[i]) == UserFeedLog, path.Base(conflicted[j]) == UserFeedLog
+		if iLog != jLog {
+			return iLog
+		}
+		return conflicted[i] < conflicted[j]
+	})
+
+	m.Renumbered = make(map[string]map[int]int)
+	for _, p := range conflicted {
+		if err := m.mergeFile(ctx, p, localBlobs[p] != "", remoteBlobs[p] != ""); err != nil {
+			return err
+		}
+	}
+
+	// Both counters may agree while feeds were renumbered, recompute them
+	for dir, mapping := range m.Renumbered {
+		if len(mapping) == 0 {
+			continue
+		}
+		p := path.Join(dir, FeedIDFile)
+		local, _, err := m.commits.readBlob(ctx, m.Local, p)
+		if err != nil {
+			return err
+		}
+		remote, _, err := m.commits.readBlob(ctx, m.Remote, p)
+		if err != nil {
+			return err
+		}
+		next := strconv.Itoa(nextFeedIDAfterMerge(ctx, m, dir, local, remote)) + "\n"
+		m.changes[p] = &next
+	}
+
+	for p, next := range m.itemCounters {
+		content := strconv.Itoa(next) + "\n"
+		m.changes[p] = &content
+	}
+
+	// Files changed only locally that cite renumbered feeds or name
+	// renumbered items must follow the new numbers; the renumbered items
+	// move to files named after their new IDs
+	for p, l := range localBlobs {
+		dir := branchDirOf(p)
+		feeds, ids := m.Renumbered[dir], m.RenumberedItems[dir]
+		if len(feeds)+len(ids) == 0 || baseBlobs[p] == l {
+			continue
+		}
+		if _, merged := m.changes[p]; merged {
+			continue
+		}
+		content, _, err := m.commits.readBlob(ctx, m.Local, p)
+		if err != nil {
+			return err
+		}
+		renumbered := renumberItemRefs(renumberCitations(content, feeds), ids)
+		target := p
+		if _, isItem := items.TypeOfPath(strings.TrimPrefix(p, dir+"/")); isItem {
+			target = renumberItemRefs(p, ids)
+		}
+		if target != p {
+			m.changes[p] = nil
+		}
+		if renumbered != content || target != p {
+			m.changes[target] = &renumbered
+		}
+	}
+	return nil
+}
+
+// renumberItems finds the items both sides filed under the same ID since
+// the base, such as local Issues/issue-004-foo.md and remote
+// Issues/issue-004-bar.md, and gives the local ones new IDs after the
+// higher counter, as mergeFeedLog does for feeds. mergeFile and
+// mergeTrees then rewrite the local changes naming them.
+func (m *Merge) renumberItems(ctx context.Context, baseBlobs, localBlobs, remoteBlobs map[string]string) error {
+	m.RenumberedItems = make(map[string]map[string]string)
+	m.itemCounters = make(map[string]int)
+
+	// itemIDs returns the IDs of the item files of a listing, per branch
+	// directory
+	itemIDs := func(blobs map[string]string) map[string]map[string]bool {
+		ids := make(map[string]map[string]bool)
+		for p := range blobs {
+			dir, rel, _ := strings.Cut(p, "/")
+			if _, ok := items.TypeOfPath(rel); !ok {
+				continue
+			}
+			if ids[dir] == nil {
+				ids[dir] = make(map[string]bool)
+			}
+			ids[dir][items.IDFromPath(rel)] = true
+		}
+		return ids
+	}
+	baseIDs, remoteIDs := itemIDs(baseBlobs), itemIDs(remoteBlobs)
+
+	var colliding []string
+	for p := range localBlobs {
+		dir, rel, _ := strings.Cut(p, "/")
+		if _, ok := items.TypeOfPath(rel); !ok {
+			continue
+		}
+		if _, inBase := baseBlobs[p]; inBase {
+			continue
+		}
+		if _, inRemote := remoteBlobs[p]; inRemote {
+			continue
+		}
+		id := items.IDFromPath(rel)
+		if remoteIDs[dir][id] && !baseIDs[dir][id] {
+			colliding = append(colliding, p)
+		}
+	}
+	sort.Slice(colliding, func(i, j int) bool {
+		a, b := colliding[i], colliding[j]
+		if na, nb := items.IDNumber(items.IDFromPath(a)), items.IDNumber(items.IDFromPath(b)); na != nb {
+			return na < nb
+		}
+		return a < b
+	})
+
+	for _, p := range colliding {
+		dir, rel, _ := strings.Cut(p, "/")
+		typ, _ := items.TypeOfPath(rel)
+		counter := path.Join(dir, ItemIDFile(typ))
+		if m.itemCounters[counter] == 0 {
+			next, err := nextItemIDAfterMerge(ctx, m, dir, typ, localBlobs, remoteBlobs)
+			if err != nil {
+				return err
+			}
+			m.itemCounters[counter] = next
+		}
+
+		if m.RenumberedItems[dir] == nil {
+			m.RenumberedItems[dir] = make(map[string]string)
+		}
+		m.RenumberedItems[dir][items.IDFromPath(rel)] = items.FormatID(typ, m.itemCounters[counter])
+		m.itemCounters[counter]++
+	}
+	return nil
+}
+
+// nextItemIDAfterMerge returns the first ID of an item type free on both
+// sides: after both counters and after every item filed on either side
+func nextItemIDAfterMerge(ctx context.Context, m *Merge, dir string, typ items.Type, localBlobs, remoteBlobs map[string]string) (int, error) {
+	next := 1
+	counter := path.Join(dir, ItemIDFile(typ))
+	for _, commit := range []string{m.Local, m.Remote} {
+		content, _, err := m.commits.readBlob(ctx, commit, counter)
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(content)); err == nil && n > next {
+			next = n
+		}
+	}
+	for _, blobs := range []map[string]string{localBlobs, remoteBlobs} {
+		for p := range blobs {
+			rel, ok := strings.CutPrefix(p, dir+"/")
+			if t, isItem := items.TypeOfPath(rel); !ok || !isItem || t != typ {
+				continue
+			}
+			if n := items.IDNumber(items.IDFromPath(rel)) + 1; n > next {
+				next = n
+			}
+		}
+	}
+	return next, nil
+}
+
+// renumberItemRefs rewrites the IDs of renumbered items according to
+// mapping, in content or in a path
+func renumberItemRefs(content string, mapping map[string]string) string {
+	if len(mapping) == 0 {
+		return content
+	}
+	ids := make([]string, 0, len(mapping))
+	for id := range mapping {
+		ids = append(ids, regexp.QuoteMeta(id))
+	}
+	sort.Strings(ids)
+	re := regexp.MustCompile(`\b(` + strings.Join(ids, "|") + `)\b`)
+	return re.ReplaceAllStringFunc(content, func(id string) string {
+		return mapping[id]
+	})
+}
+
+// mergeFile merges one path changed on both sides according to its type
+func (m *Merge) mergeFile(ctx context.Context, p string, inLocal, inRemote bool) error {
+	var blobs [3]string
+	for n, commit := range []string{m.Base, m.Local, m.Remote} {
+		content, _, err := m.commits.readBlob(ctx, commit, p)
+		if err != nil {
+			return err
+		}
+		blobs[n] = content
+	}
+	base, local, remote := blobs[0], blobs[1], blobs[2]
+	dir := branchDirOf(p)
+	// Local items that collided with remote ones have new IDs; the local
+	// side only ever names its own items under the old ones
+	local = renumberItemRefs(local, m.RenumberedItems[dir])
+	f := &MergedFile{Path: p, Local: local, Remote: remote}
+	m.Files = append(m.Files, f)
+
+	switch {
+	case !inLocal || !inRemote:
+		// Deleted on one side and modified on the other: keep the modification
+		content := local
+		if !inLocal {
+			content = remote
+		}
+		f.Resolution, f.Merged = ResolvedText, content
+		m.changes[p] = &content
+		return nil
+
+	case p == RegistryFile:
+		// Directories are derived from branch names, both sides agree on
+		// every entry they share
+		registry, err := parseBranchRegistry(local)
+		if err != nil {
+			return err
+		}
+		theirs, err := parseBranchRegistry(remote)
+		if err != nil {
+			return err
+		}
+		for dir, branch := range theirs {
+			registry[dir] = branch
+		}
+		f.Resolution, f.Merged = ResolvedUnion, registry.String()
+		m.changes[p] = &f.Merged
+		return nil
+
+	case path.Base(p) == UserFeedLog:
+		if merged, mapping, ok := mergeFeedLog(base, local, remote); ok {
+			m.Renumbered[dir] = mapping
+			f.Resolution, f.Merged = ResolvedAppend, merged
+			m.changes[p] = &merged
+			return nil
+		}
+
+	case path.Base(p) == DecisionLog:
+		// Append-only; decisions are independent, keep both sides'
+		if strings.HasPrefix(local, base) && strings.HasPrefix(remote, base) {
+			f.Resolution, f.Merged = ResolvedAppend, remote+strings.TrimPrefix(local, base)
+			m.changes[p] = &f.Merged
+			return nil
+		}
+
+	case path.Base(p) == FeedIDFile:
+		f.Resolution = ResolvedRenumber
+		f.Merged = strconv.Itoa(nextFeedIDAfterMerge(ctx, m, dir, local, remote)) + "\n"
+		m.changes[p] = &f.Merged
+		return nil
+
+	case isIndexFile(strings.TrimPrefix(p, dir+"/")):
+		// Keep every item either side indexed, in its latest version. Two
+		// files under one ID that renumbering didn't settle, e.g. filed by
+		// hand, need the user to review.
+		if merged, err := mergeIndex(local, remote); err == nil {
+			f.Resolution, f.Merged = ResolvedUnion, merged
+			if len(duplicateIndexIDs(merged)) > 0 {
+				f.Resolution = NeedsReview
+			}
+			m.changes[p] = &f.Merged
+			return nil
+		}
+
+	case isItemIDFile(path.Base(p)):
+		// Both sides allocated IDs, continue after the higher counter
+		l, _ := strconv.Atoi(strings.TrimSpace(local))
+		r, _ := strconv.Atoi(strings.TrimSpace(remote))
+		f.Resolution, f.Merged = ResolvedNewer, strconv.Itoa(max(l, r, 1, m.itemCounters[p]))+"\n"
+		m.changes[p] = &f.Merged
+		return nil
+
+	case path.Base(p) == SyncStateFile:
+		content := local
+		if l, r := strings.TrimSpace(local), strings.TrimSpace(remote); l != "" && r != "" && isAncestor(ctx, l, r) {
+			content = remote
+		}
+		f.Resolution, f.Merged = ResolvedNewer, content
+		m.changes[p] = &content
+		return nil
+	}
+
+	// META.md, item files and anything else: per-file three-way textual merge
+	local = renumberCitations(local, m.Renumbered[dir])
+	merged, clean, err := mergeText(ctx, base, local, remote)
+	if err != nil {
+		return err
+	}
+	f.Local, f.Merged = local, merged
+	if clean {
+		f.Resolution = ResolvedText
+	} else {
+		f.Resolution = NeedsReview
+	}
+	m.changes[p] = &merged
+	return nil
+}
+
+// mergeFeedLog merges two append-only feed logs sharing the base as prefix.
+// Feeds added locally are renumbered to follow the remote ones.
+func mergeFeedLog(base, local, remote string) (string, map[int]int, bool) {
+	if !strings.HasPrefix(local, base) || !strings.HasPrefix(remote, base) {
+		return "", nil, false
+	}
+
+	next := maxFeedID(remote) + 1
+	mapping := make(map[int]int)
+	added := feedHeaderRe.ReplaceAllStringFunc(local[len(base):], func(header string) string {
+		id, _ := strconv.Atoi(feedHeaderRe.FindStringSubmatch(header)[1])
+		mapping[id] = next
+		next++
+		return fmt.Sprintf("=== Feed #%d ===", mapping[id])
+	})
+	return remote + added, mapping, true
+}
+
+// maxFeedID returns the highest feed ID recorded in a feed log
+func maxFeedID(log string) int {
+	highest := 0
+	for _, match := range feedHeaderRe.FindAllStringSubmatch(log, -1) {
+		if id, err := strconv.Atoi(match[1]); err == nil && id > highest {
+			highest = id
+		}
+	}
+	return highest
+}
+
+// nextFeedIDAfterMerge returns the feed counter following every merged feed
+func nextFeedIDAfterMerge(ctx context.Context, m *Merge, dir, local, remote string) int {
+	next := 1
+	for _, counter := range []string{local, remote} {
+		if id, err := strconv.Atoi(strings.TrimSpace(counter)); err == nil && id > next {
+			next = id
+		}
+	}
+	if log := m.changes[path.Join(dir, UserFeedLog)]; log != nil {
+		if id := maxFeedID(*log) + 1; id > next {
+			next = id
+		}
+	}
+	return next
+}
+
+// renumberCitations rewrites [Feed #N] citations according to mapping
+func renumberCitations(content string, mapping map[int]int) string {
+	if len(mapping) == 0 {
+		return content
+	}
+	return feedCitationRe.ReplaceAllStringFunc(content, func(citation string) string {
+		id, _ := strconv.Atoi(feedCitationRe.FindStringSubmatch(citation)[1])
+		if renumbered, ok := mapping[id]; ok {
+			return fmt.Sprintf("Feed #%d", renumbered)
+		}
+		return citation
+	})
+}
+
+// mergeText runs a three-way textual merge with git merge-file.
+// Conflicting hunks are left in the result with conflict markers.
+func mergeText(ctx context.Context, base, local, remote string) (string, bool, error) {
+	tmpDir, err := os.MkdirTemp("", "lm-merge-")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := make([]string, 3)
+	for i, content := range []string{local, base, remote} {
+		files[i] = filepath.Join(tmpDir, strconv.Itoa(i))
+		if err := os.WriteFile(files[i], []byte(content), 0644); err != nil {
+			return "", false, fmt.Errorf("failed to write merge input: %w", err)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "merge-file", "-p", "-L", "local", "-L", "base", "-L", "remote", files[0], files[1], files[2])
+	output, err := cmd.Output()
+	if err == nil {
+		return string(output), true, nil
+	}
+
+	// merge-file exits with the number of conflicts, negative values are errors
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return string(output), false, nil
+	}
+	return "", false, fmt.Errorf("failed to merge: %w", err)
+}
+
+// ApplyMerge lands a prepared merge on the local shadow branch
+func ApplyMerge(ctx context.Context, m *Merge) error {
+	if m.UpToDate {
+		return nil
+	}
+	if len(m.Conflicts()) > 0 {
+		return fmt.Errorf("%d META file(s) still need review", len(m.Conflicts()))
+	}
+
+	if m.FastForward {
+		if _, err := runGit(ctx, "", "update-ref", "-m", "Fast-forward META from remote", shadowRef, m.Remote, m.Local); err != nil {
+			return ErrMetaMovedDuringMerge
+		}
+		return nil
+	}
+
+	blobs := make(map[string]*string, len(m.changes))
+	for p, content := range m.changes {
+		if content == nil {
+			blobs[p] = nil
+			continue
+		}
+		sha, err := hashObject(ctx, *content)
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", p, err)
+		}
+		blobs[p] = &sha
+	}
+
+	tree, _, err := buildTree(ctx, m.Local+"^{tree}", blobs)
+	if err != nil {
+		return fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	subject := fmt.Sprintf("Merge remote META %s into %s", shortID(m.Remote), shortID(m.Local))
+	message := completeMessage(ctx, (&Trailers{Operation: OpMerge}).Message(subject))
+	commit, err := runGitTrimmed(ctx, "", "commit-tree", tree, "-p", m.Local, "-p", m.Remote, "-m", message)
+	if err != nil {
+		return fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	if _, err := runGit(ctx, "", "update-ref", "-m", subject, shadowRef, commit, m.Local); err != nil {
+		return ErrMetaMovedDuringMerge
+	}
+	return nil
+}
+
+// shortID abbreviates a commit ID for messages
+func shortID(id string) string {
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
@@ -0,0 +1,369 @@
+package meta
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/laddermoon/laddermoon/pkg/items"
+)
+
+// testBranchDir is the branch directory the stores of the merge tests
+// stand for
+const testBranchDir = "main"
+
+// memCommits holds the files of commits in memory, by commit and path
+type memCommits map[string]map[string]string
+
+func (c memCommits) listBlobs(ctx context.Context, commit string) (map[string]string, error) {
+	blobs := make(map[string]string)
+	for p, content := range c[commit] {
+		sum := sha1.Sum([]byte(content))
+		blobs[p] = hex.EncodeToString(sum[:])
+	}
+	return blobs, nil
+}
+
+func (c memCommits) readBlob(ctx context.Context, commit, p string) (string, bool, error) {
+	content, ok := c[commit][p]
+	return content, ok, nil
+}
+
+// snapshot returns the files of a store as the shadow branch holds them,
+// under testBranchDir
+func snapshot(t *testing.T, s *MemStore) map[string]string {
+	t.Helper()
+	files, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := make(map[string]string, len(files))
+	for _, f := range files {
+		content, err := s.Read(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree[path.Join(testBranchDir, f)] = content
+	}
+	return tree
+}
+
+// cloneStore returns a new store holding the files of s
+func cloneStore(t *testing.T, s *MemStore) *MemStore {
+	t.Helper()
+	clone := NewMemStore(context.Background())
+	for p, content := range snapshot(t, s) {
+		clone.Write(strings.TrimPrefix(p, testBranchDir+"/"), content)
+	}
+	if err := clone.Commit("Clone"); err != nil {
+		t.Fatal(err)
+	}
+	return clone
+}
+
+// fileIssue files an issue under the next ID, as a skill does
+func fileIssue(t *testing.T, s *MemStore, title, body string) *items.Item {
+	t.Helper()
+	ids, err := ReserveItemIDs(s, items.TypeIssue, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := items.New(items.TypeIssue, ids[0], title, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	item.Path = path.Join(items.TypeIssue.Dir(), items.FileName(item.ID, title))
+	item.Body += body
+	if err := saveItems(s, item); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Commit("File " + item.ID); err != nil {
+		t.Fatal(err)
+	}
+	return item
+}
+
+// mergeStores merges the changes local and remote made since base the way
+// PrepareMerge merges commits, and applies the result to local
+func mergeStores(t *testing.T, base, local, remote *MemStore) *Merge {
+	t.Helper()
+	m := &Merge{
+		Base:    "base",
+		Local:   "local",
+		Remote:  "remote",
+		changes: make(map[string]*string),
+		commits: memCommits{
+			"base":   snapshot(t, base),
+			"local":  snapshot(t, local),
+			"remote": snapshot(t, remote),
+		},
+	}
+	if err := m.mergeTrees(context.Background()); err != nil {
+		t.Fatalf("mergeTrees() error: %v", err)
+	}
+	for p, content := range m.changes {
+		p = strings.TrimPrefix(p, testBranchDir+"/")
+		if content == nil {
+			local.Delete(p)
+		} else {
+			local.Write(p, *content)
+		}
+	}
+	if err := local.Commit("Merge"); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMergeRenumbersLocalFeedsAndItems(t *testing.T) {
+	base := NewMemStore(context.Background())
+	if err := base.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordFeed(base, 1, "base feed"); err != nil {
+		t.Fatal(err)
+	}
+	fileIssue(t, base, "Base issue", "")
+
+	local, remote := cloneStore(t, base), cloneStore(t, base)
+	if err := RecordFeed(local, 2, "local feed"); err != nil {
+		t.Fatal(err)
+	}
+	fileIssue(t, local, "Local issue", "\nFrom [Feed #2], see issue-001.\n")
+	local.Write(MetaFileName, "Uses PostgreSQL [Feed #2]\n")
+	if err := local.Commit("Update META.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordFeed(remote, 2, "remote feed"); err != nil {
+		t.Fatal(err)
+	}
+	fileIssue(t, remote, "Remote issue", "")
+
+	m := mergeStores(t, base, local, remote)
+
+	if want := map[int]int{2: 3}; !reflect.DeepEqual(m.Renumbered[testBranchDir], want) {
+		t.Errorf("Renumbered = %v, want %v", m.Renumbered[testBranchDir], want)
+	}
+	if want := map[string]string{"issue-002": "issue-003"}; !reflect.DeepEqual(m.RenumberedItems[testBranchDir], want) {
+		t.Errorf("RenumberedItems = %v, want %v", m.RenumberedItems[testBranchDir], want)
+	}
+	if conflicts := m.Conflicts(); len(conflicts) != 0 {
+		t.Errorf("Conflicts() = %d files, want none", len(conflicts))
+	}
+
+	log, _ := local.Read(UserFeedLog)
+	if got := feedHeaderRe.FindAllString(log, -1); !reflect.DeepEqual(got, []string{"=== Feed #1 ===", "=== Feed #2 ===", "=== Feed #3 ==="}) {
+		t.Errorf("feed log headers = %q, want feeds 1 to 3", got)
+	}
+	if !strings.Contains(log, "remote feed\n===\n\n=== Feed #3 ===") || !strings.Contains(log, "local feed") {
+		t.Errorf("feed log = %q, want the remote feed as #2 and the local one as #3", log)
+	}
+	if next, err := GetNextFeedID(local); err != nil || next != 4 {
+		t.Errorf("GetNextFeedID() = %d, %v, want 4", next, err)
+	}
+	if content, _ := local.Read(MetaFileName); content != "Uses PostgreSQL [Feed #3]\n" {
+		t.Errorf("META.md = %q, want the citation renumbered", content)
+	}
+
+	all, err := items.LoadAll(local, items.TypeIssue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, item := range all {
+		got[item.ID] = item.Path
+	}
+	want := map[string]string{
+		"issue-001": "Issues/issue-001-base-issue.md",
+		"issue-002": "Issues/issue-002-remote-issue.md",
+		"issue-003": "Issues/issue-003-local-issue.md",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+	renumbered, err := items.Find(local, "issue-003")
+	if err != nil || renumbered == nil {
+		t.Fatalf("Find(issue-003) = %v, %v", renumbered, err)
+	}
+	if !strings.Contains(renumbered.Body, "From [Feed #3], see issue-001.") {
+		t.Errorf("issue-003 body = %q, want the feed renumbered and other IDs kept", renumbered.Body)
+	}
+	if next, err := GetNextItemID(local, items.TypeIssue); err != nil || next != 4 {
+		t.Errorf("GetNextItemID() = %d, %v, want 4", next, err)
+	}
+
+	// The index was merged as a union that agrees with the files
+	if stale, err := StaleIndexes(local); err != nil || len(stale) != 0 {
+		t.Errorf("StaleIndexes() = %v, %v, want none", stale, err)
+	}
+}
+
+func TestMergeIndexFlagsDuplicateIDs(t *testing.T) {
+	base := NewMemStore(context.Background())
+	if err := base.Init(); err != nil {
+		t.Fatal(err)
+	}
+	fileIssue(t, base, "Base issue", "")
+
+	local, remote := cloneStore(t, base), cloneStore(t, base)
+	fileIssue(t, remote, "Remote issue", "")
+	// Filed by hand under an ID the base already had, so it isn't renumbered
+	item := items.New(items.TypeIssue, "issue-001", "Hand filed", time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC))
+	item.Path = "Issues/issue-001-hand-filed.md"
+	if err := saveItems(local, item); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.Commit("File by hand"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := mergeStores(t, base, local, remote)
+	conflicts := m.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Path != path.Join(testBranchDir, IndexFile(items.TypeIssue)) {
+		t.Fatalf("Conflicts() = %+v, want the issue index", conflicts)
+	}
+	if ids := duplicateIndexIDs(conflicts[0].Merged); !reflect.DeepEqual(ids, []string{"issue-001"}) {
+		t.Errorf("duplicate IDs = %v, want issue-001", ids)
+	}
+}
+
+func TestMergeFeedLog(t *testing.T) {
+	feed := func(id int, content string) string {
+		return "\n=== Feed #" + strconv.Itoa(id) + " ===\nContent:\n" + content + "\n===\n"
+	}
+	base := feed(1, "one")
+	tests := []struct {
+		name        string
+		local       string
+		remote      string
+		want        string
+		wantMapping map[int]int
+		ok          bool
+	}{
+		{
+			name:        "both appended",
+			local:       base + feed(2, "local a") + feed(3, "local b [Feed #2]"),
+			remote:      base + feed(2, "remote"),
+			want:        base + feed(2, "remote") + feed(3, "local a") + feed(4, "local b [Feed #2]"),
+			wantMapping: map[int]int{2: 3, 3: 4},
+			ok:          true,
+		},
+		{
+			name:        "only remote appended",
+			local:       base,
+			remote:      base + feed(2, "remote"),
+			want:        base + feed(2, "remote"),
+			wantMapping: map[int]int{},
+			ok:          true,
+		},
+		{
+			name:   "history rewritten",
+			local:  feed(1, "edited"),
+			remote: base + feed(2, "remote"),
+			ok:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, mapping, ok := mergeFeedLog(base, tt.local, tt.remote)
+			if ok != tt.ok {
+				t.Fatalf("mergeFeedLog() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mergeFeedLog() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if !reflect.DeepEqual(mapping, tt.wantMapping) {
+				t.Errorf("mapping = %v, want %v", mapping, tt.wantMapping)
+			}
+		})
+	}
+}
+
+func TestRenumberCitations(t *testing.T) {
+	mapping := map[int]int{2: 3, 3: 4}
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"[Feed #2] and [Feed #3]", "[Feed #3] and [Feed #4]"},
+		{"[Serves: Feed #2]", "[Serves: Feed #3]"},
+		{"[Feed #1] and [Feed #20]", "[Feed #1] and [Feed #20]"},
+	}
+	for _, tt := range tests {
+		if got := renumberCitations(tt.content, mapping); got != tt.want {
+			t.Errorf("renumberCitations(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestRenumberItemRefs(t *testing.T) {
+	mapping := map[string]string{"issue-002": "issue-004", "task-001": "task-002"}
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Issues/issue-002-slow.md", "Issues/issue-004-slow.md"},
+		{"blocked by task-001, see issue-002", "blocked by task-002, see issue-004"},
+		{"issue-0021 and xissue-002", "issue-0021 and xissue-002"},
+		{"task-001 task-002", "task-002 task-002"},
+	}
+	for _, tt := range tests {
+		if got := renumberItemRefs(tt.content, mapping); got != tt.want {
+			t.Errorf("renumberItemRefs(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMergeIndex(t *testing.T) {
+	older := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	index := func(entries ...IndexEntry) string {
+		content, err := formatIndex(entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+	one := IndexEntry{ID: "issue-001", Status: "open", Title: "One", Updated: older, Path: "Issues/issue-001-one.md"}
+	oneClosed := one
+	oneClosed.Status, oneClosed.Updated = "resolved", newer
+	two := IndexEntry{ID: "issue-002", Status: "open", Title: "Two", Updated: older, Path: "Issues/issue-002-two.md"}
+	twoAgain := IndexEntry{ID: "issue-002", Status: "open", Title: "Two again", Updated: older, Path: "Issues/issue-002-again.md"}
+
+	tests := []struct {
+		name       string
+		local      string
+		remote     string
+		want       string
+		duplicates []string
+	}{
+		{"union", index(one), index(two), index(one, two), nil},
+		{"newer local entry wins", index(oneClosed), index(one, two), index(oneClosed, two), nil},
+		{"newer remote entry wins", index(one), index(oneClosed), index(oneClosed), nil},
+		{"two files under one ID", index(two), index(twoAgain), index(two, twoAgain), []string{"issue-002"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeIndex(tt.local, tt.remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("mergeIndex() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if ids := duplicateIndexIDs(got); !reflect.DeepEqual(ids, tt.duplicates) {
+				t.Errorf("duplicateIndexIDs() = %v, want %v", ids, tt.duplicates)
+			}
+		})
+	}
+
+	if _, err := mergeIndex("not json\n", index(one)); err == nil {
+		t.Error("mergeIndex(invalid) succeeded, want an error")
+	}
+}
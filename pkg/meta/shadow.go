@@ -0,0 +1,276 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// shadowRef is the fully qualified ref of the shadow branch
+const shadowRef = "refs/heads/" + BranchName
+
+// maxCommitAttempts bounds how often a shadow commit is retried when
+// another process moves the shadow branch between read and update-ref
+const maxCommitAttempts = 5
+
+// treeEntry is a single entry of a git tree object
+type treeEntry struct {
+	mode string
+	typ  string
+	sha  string
+	name string
+}
+
+// gitCommand prepares a git command that is killed when ctx is done,
+// optionally feeding stdin
+func gitCommand(ctx context.Context, stdin string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	return cmd
+}
+
+// runGit runs a git command, optionally feeding stdin, and returns its stdout
+func runGit(ctx context.Context, stdin string, args ...string) (string, error) {
+	cmd := gitCommand(ctx, stdin, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", newGitError(args, stderr.String(), err)
+	}
+	return string(output), nil
+}
+
+// resolveShadowTip returns the commit the shadow branch points to,
+// or an empty string if the branch does not exist yet
+func resolveShadowTip(ctx context.Context) string {
+	output, err := runGit(ctx, "", "rev-parse", "--verify", "--quiet", shadowRef)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// readBlob reads a file at the given path from a commit through the shared
+// cat-file process. The second return value reports whether the file
+// exists; it is false without an error only if the file is absent, so a
+// failed read is never taken for an empty file.
+func readBlob(ctx context.Context, commit, path string) (string, bool, error) {
+	if commit == "" {
+		return "", false, nil
+	}
+	if r, err := reader(ctx); err == nil {
+		if content, ok, err := r.ReadFile(commit, path); err == nil {
+			return content, ok, nil
+		}
+	}
+
+	// Without the shared process, tell an absent file from a failed read
+	// before reading it
+	if _, err := runGit(ctx, "", "rev-parse", "--verify", "--quiet", commit+":"+path); err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	output, err := runGit(ctx, "", "cat-file", "blob", commit+":"+path)
+	if err != nil {
+		return "", false, err
+	}
+	return output, true, nil
+}
+
+// hashObject writes content into the object database and returns the blob ID
+func hashObject(ctx context.Context, content string) (string, error) {
+	output, err := runGit(ctx, content, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// lsTree lists the direct entries of a tree-ish
+func lsTree(ctx context.Context, treeish string) ([]treeEntry, error) {
+	output, err := runGit(ctx, "", "ls-tree", "-z", treeish)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []treeEntry
+	for _, record := range strings.Split(output, "\x00") {
+		if record == "" {
+			continue
+		}
+		info, name, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, treeEntry{mode: fields[0], typ: fields[1], sha: fields[2], name: name})
+	}
+	return entries, nil
+}
+
+// mkTree creates a tree object from the given entries
+func mkTree(ctx context.Context, entries []treeEntry) (string, error) {
+	var input strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&input, "%s %s %s\t%s\x00", e.mode, e.typ, e.sha, e.name)
+	}
+	return runGitTrimmed(ctx, input.String(), "mktree", "-z")
+}
+
+// runGitTrimmed runs a git command and returns its stdout without surrounding whitespace
+func runGitTrimmed(ctx context.Context, stdin string, args ...string) (string, error) {
+	output, err := runGit(ctx, stdin, args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// buildTree applies blob changes to a base tree and returns the new tree ID.
+// Keys of changes are slash-separated paths relative to the tree, a nil value
+// deletes the path. Directories left without entries are dropped. The second
+// return value reports whether the resulting tree is empty.
+func buildTree(ctx context.Context, baseTree string, changes map[string]*string) (string, bool, error) {
+	entries := make(map[string]treeEntry)
+	if baseTree != "" {
+		list, err := lsTree(ctx, baseTree)
+		if err != nil {
+			return "", false, err
+		}
+		for _, e := range list {
+			entries[e.name] = e
+		}
+	}
+
+	subChanges := make(map[string]map[string]*string)
+	for p, blob := range changes {
+		if dir, rest, ok := strings.Cut(p, "/"); ok {
+			if subChanges[dir] == nil {
+				subChanges[dir] = make(map[string]*string)
+			}
+			subChanges[dir][rest] = blob
+			continue
+		}
+		if blob == nil {
+			delete(entries, p)
+			continue
+		}
+		entries[p] = treeEntry{mode: "100644", typ: "blob", sha: *blob, name: p}
+	}
+
+	for dir, sub := range subChanges {
+		subBase := ""
+		if e, ok := entries[dir]; ok && e.typ == "tree" {
+			subBase = e.sha
+		}
+		sha, empty, err := buildTree(ctx, subBase, sub)
+		if err != nil {
+			return "", false, err
+		}
+		if empty {
+			delete(entries, dir)
+			continue
+		}
+		entries[dir] = treeEntry{mode: "040000", typ: "tree", sha: sha, name: dir}
+	}
+
+	list := make([]treeEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sha, err := mkTree(ctx, list)
+	if err != nil {
+		return "", false, err
+	}
+	return sha, len(list) == 0, nil
+}
+
+// commitShadow commits a set of file changes onto the shadow branch without
+// touching the working directory or the index.
+//
+// edit receives the commit the changes are based on ("" when the shadow
+// branch does not exist yet) and returns the new content of each changed
+// path, keyed by its full path on the shadow branch; a nil content deletes
+// the path. The message gets the LM trailers every META commit carries.
+// The branch is moved with a compare-and-swap update-ref, and edit
+// is called again on the new tip if another writer got there first.
+func commitShadow(ctx context.Context, message string, edit func(parent string) (map[string]*string, error)) error {
+	message = completeMessage(ctx, message)
+	subject, _ := ParseCommitMessage(message)
+
+	for attempt := 0; attempt < maxCommitAttempts; attempt++ {
+		parent := resolveShadowTip(ctx)
+
+		changes, err := edit(parent)
+		if err != nil {
+			return err
+		}
+
+		blobs := make(map[string]*string, len(changes))
+		for p, content := range changes {
+			if content == nil {
+				blobs[p] = nil
+				continue
+			}
+			sha, err := hashObject(ctx, *content)
+			if err != nil {
+				return fmt.Errorf("failed to store %s: %w", p, err)
+			}
+			blobs[p] = &sha
+		}
+
+		baseTree := ""
+		if parent != "" {
+			baseTree = parent + "^{tree}"
+		}
+		tree, _, err := buildTree(ctx, baseTree, blobs)
+		if err != nil {
+			return fmt.Errorf("failed to build tree: %w", err)
+		}
+
+		if parent != "" {
+			parentTree, err := runGitTrimmed(ctx, "", "rev-parse", baseTree)
+			if err == nil && parentTree == tree {
+				// Nothing changed, don't create an empty commit
+				return nil
+			}
+		}
+
+		args := []string{"commit-tree", tree, "-m", message}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		commit, err := runGitTrimmed(ctx, "", args...)
+		if err != nil {
+			return fmt.Errorf("failed to commit: %w", err)
+		}
+
+		// Publishing is the point of no return; an interrupted command
+		// leaves only unreachable objects behind
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// An empty old value makes update-ref verify that the ref does not exist yet
+		if _, err := runGit(ctx, "", "update-ref", "-m", subject, shadowRef, commit, parent); err != nil {
+			if resolveShadowTip(ctx) != parent {
+				continue
+			}
+			return fmt.Errorf("failed to update %s: %w", BranchName, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to update %s: branch kept moving after %d attempts", BranchName, maxCommitAttempts)
+}
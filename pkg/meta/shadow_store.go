@@ -0,0 +1,198 @@
+package meta
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// ShadowStore keeps the META of one git branch in its directory on the
+// laddermoon-meta shadow branch
+type ShadowStore struct {
+	ctx       context.Context
+	branch    string
+	branchDir string
+	tx        *Tx
+	snap      *treeSnapshot
+}
+
+// NewShadowStore opens the shadow branch store for the current branch.
+// Git runs under ctx for as long as the store is used.
+func NewShadowStore(ctx context.Context) (*ShadowStore, error) {
+	branch, err := GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return &ShadowStore{ctx: ctx, branch: branch, branchDir: getBranchMetaDir(branch)}, nil
+}
+
+// Branch returns the git branch the store belongs to
+func (s *ShadowStore) Branch() string {
+	return s.branch
+}
+
+// Location describes where the store keeps its files
+func (s *ShadowStore) Location() string {
+	return fmt.Sprintf("%s:%s/", BranchName, s.branchDir)
+}
+
+// Initialized reports whether the branch directory exists on the shadow branch
+func (s *ShadowStore) Initialized() bool {
+	snap, err := s.snapshot()
+	return err == nil && snap.exists
+}
+
+// snapshot returns the listing of the branch directory at the current
+// shadow branch tip. The listing is only redone when the tip moved, so a
+// command reading many files runs ls-tree once and reads all blobs
+// through the shared cat-file process.
+func (s *ShadowStore) snapshot() (*treeSnapshot, error) {
+	r, err := reader(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := r.Resolve(shadowRef)
+	if err != nil {
+		return nil, err
+	}
+	if s.snap == nil || s.snap.commit != tip {
+		snap, err := snapshotDir(s.ctx, tip, s.branchDir)
+		if err != nil {
+			return nil, err
+		}
+		s.snap = snap
+	}
+	return s.snap, nil
+}
+
+// Init creates META.md and the item directories for the branch,
+// creating the shadow branch as an orphan when it doesn't exist yet
+func (s *ShadowStore) Init() error {
+	if _, err := GetGitRoot(s.ctx); err != nil {
+		return err
+	}
+
+	// Create META.md (empty file) and directories with .gitkeep in branch directory
+	empty := ""
+	changes := map[string]*string{
+		path.Join(s.branchDir, MetaFileName): &empty,
+	}
+	for _, dir := range []string{"Questions", "Issues", "Proposals"} {
+		changes[path.Join(s.branchDir, dir, ".gitkeep")] = &empty
+	}
+
+	commitMsg := (&Trailers{Operation: OpInit}).Message(fmt.Sprintf("Initialize LadderMoon META for branch: %s", s.branch))
+	err := commitShadow(s.ctx, commitMsg, func(parent string) (map[string]*string, error) {
+		if err := registerBranch(s.ctx, parent, changes, s.branch, s.branchDir); err != nil {
+			return nil, err
+		}
+		return changes, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize META: %w", err)
+	}
+	return nil
+}
+
+// Read returns the content of a file, or ErrNotFound if it doesn't exist
+func (s *ShadowStore) Read(filename string) (string, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return "", err
+	}
+	if snap.commit == "" {
+		return "", ErrNotInitialized
+	}
+
+	id, ok := snap.blobs[filename]
+	if !ok {
+		return "", notFound(filename)
+	}
+	r, err := reader(s.ctx)
+	if err != nil {
+		return "", err
+	}
+	content, ok, err := r.Blob(id)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", corrupt(filename, "object %s is missing", id)
+	}
+	return content, nil
+}
+
+// List returns the paths of all files in the branch directory
+func (s *ShadowStore) List() ([]string, error) {
+	snap, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if snap.commit == "" {
+		return nil, ErrNotInitialized
+	}
+
+	result := make([]string, 0, len(snap.blobs))
+	for filename := range snap.blobs {
+		result = append(result, filename)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// pending returns the transaction collecting staged changes
+func (s *ShadowStore) pending() *Tx {
+	if s.tx == nil {
+		s.tx = &Tx{ctx: s.ctx, branch: s.branch, branchDir: s.branchDir}
+	}
+	return s.tx
+}
+
+// Write stages overwriting a file with content
+func (s *ShadowStore) Write(filename, content string) error {
+	s.pending().WriteFile(filename, content)
+	return nil
+}
+
+// Append stages appending content to a file
+func (s *ShadowStore) Append(filename, content string) error {
+	s.pending().AppendToFile(filename, content)
+	return nil
+}
+
+// Delete stages removing a file
+func (s *ShadowStore) Delete(filename string) error {
+	s.pending().DeleteFile(filename)
+	return nil
+}
+
+// Rollback discards all staged changes
+func (s *ShadowStore) Rollback() {
+	if s.tx != nil {
+		s.tx.Rollback()
+		s.tx = nil
+	}
+}
+
+// History returns the shadow branch commits that changed a file
+func (s *ShadowStore) History(filename string) ([]Revision, error) {
+	if !IsInitialized(s.ctx) {
+		return nil, ErrNotInitialized
+	}
+	return gitHistory(s.ctx, BranchName, "--", path.Join(s.branchDir, filename))
+}
+
+// Commit lands all staged changes as one shadow branch commit
+func (s *ShadowStore) Commit(message string) error {
+	if s.tx == nil {
+		return nil
+	}
+	if !IsInitialized(s.ctx) {
+		return ErrNotInitialized
+	}
+	tx := s.tx
+	s.tx = nil
+	subject, trailers := ParseCommitMessage(message)
+	return tx.Commit(trailers.MessageWithBody(fmt.Sprintf("%s for branch %s", subject, s.branch), splitBody(message)))
+}
@@ -0,0 +1,190 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store kinds accepted by OpenStore
+const (
+	// StoreShadow keeps META on the laddermoon-meta shadow branch
+	StoreShadow = "shadow"
+	// StoreDir keeps META in the .laddermoon/ directory of the working tree
+	StoreDir = "dir"
+	// StoreMemory keeps META in memory, for tests
+	StoreMemory = "memory"
+)
+
+// StoreConfigKey is the git config key selecting the store kind
+const StoreConfigKey = "laddermoon.store"
+
+// Revision is one recorded change of a META store
+type Revision struct {
+	ID      string
+	Time    time.Time
+	Message string
+	// Body is the description between the subject and the trailers
+	Body string
+	// Trailers are the LM trailers of the change, empty for changes made
+	// before trailers were recorded
+	Trailers *Trailers
+}
+
+// MetaStore is a backend holding the META files of one git branch.
+//
+// Paths are relative to the branch's META directory and use the layout of
+// the shadow branch (META.md, UserFeed.log, Issues/...). Write, Append and
+// Delete only stage changes; Commit lands everything staged as one change.
+// A store is bound to the context it was opened with. Once that context
+// is done, Commit discards the staged changes and returns its error, so an
+// interrupted command never lands a partial change.
+type MetaStore interface {
+	// Location describes where the store keeps its files
+	Location() string
+	// Initialized reports whether META exists for the store's branch
+	Initialized() bool
+	// Init creates the initial META structure for the store's branch
+	Init() error
+	// Read returns the committed content of a file, or an error matching
+	// ErrNotFound if it doesn't exist
+	Read(filename string) (string, error)
+	// List returns the paths of all committed files
+	List() ([]string, error)
+	// Write stages overwriting a file with content
+	Write(filename, content string) error
+	// Append stages appending content to a file, creating it if needed
+	Append(filename, content string) error
+	// Delete stages removing a file
+	Delete(filename string) error
+	// History returns the revisions that changed a file, newest first.
+	// An empty filename returns the history of the whole store.
+	History(filename string) ([]Revision, error)
+	// Commit lands all staged changes as one change with the given message
+	Commit(message string) error
+	// Rollback discards all staged changes
+	Rollback()
+}
+
+// OpenStore opens the META store of the given kind for the current branch,
+// bound to ctx.
+// An empty kind uses ConfiguredStoreKind.
+func OpenStore(ctx context.Context, kind string) (MetaStore, error) {
+	if kind == "" {
+		kind = ConfiguredStoreKind(ctx)
+	}
+
+	switch kind {
+	case StoreShadow:
+		return NewShadowStore(ctx)
+	case StoreDir:
+		return NewDirStore(ctx)
+	case StoreMemory:
+		return NewMemStore(ctx), nil
+	default:
+		return nil, fmt.Errorf("unknown META store %q (want %s, %s or %s)", kind, StoreShadow, StoreDir, StoreMemory)
+	}
+}
+
+// ConfiguredStoreKind returns the store kind set in git config under
+// laddermoon.store, defaulting to the shadow branch
+func ConfiguredStoreKind(ctx context.Context) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--get", StoreConfigKey)
+	output, err := cmd.Output()
+	if err != nil {
+		return StoreShadow
+	}
+	kind := strings.TrimSpace(string(output))
+	if kind == "" {
+		return StoreShadow
+	}
+	return kind
+}
+
+// gitHistory returns the revisions of a git log query, newest first
+func gitHistory(ctx context.Context, args ...string) ([]Revision, error) {
+	logArgs := append([]string{"log", "--format=%H%x00%at%x00%s%x00%b%x1e"}, args...)
+	output, err := runGit(ctx, "", logArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Revision
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[1], 10, 64)
+		revisions = append(revisions, Revision{
+			ID:       fields[0],
+			Time:     time.Unix(unix, 0),
+			Message:  fields[2],
+			Body:     splitBody(fields[2] + "\n\n" + fields[3]),
+			Trailers: ParseTrailers(fields[3]),
+		})
+	}
+	return revisions, nil
+}
+
+// GetNextFeedID reads the next feed ID from the store. A counter that
+// can't be parsed is reported as ErrCorrupt rather than restarting at 1,
+// which would hand out IDs that are already taken.
+func GetNextFeedID(s MetaStore) (int, error) {
+	content, err := s.Read(FeedIDFile)
+	if errors.Is(err, ErrNotFound) {
+		return 1, nil // No feed recorded yet
+	}
+	if err != nil {
+		return 0, err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return 1, nil
+	}
+	id, err := strconv.Atoi(content)
+	if err != nil || id < 1 {
+		return 0, corrupt(FeedIDFile, "invalid feed ID %q", content)
+	}
+	return id, nil
+}
+
+// RecordFeed records the user input to UserFeed.log and advances the feed ID
+// in a single commit, so a recorded Feed #N always comes with .next_feed_id N+1
+func RecordFeed(s MetaStore, feedID int, content string) error {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	entry := fmt.Sprintf("\n=== Feed #%d ===\nDate: %s\nContent:\n%s\n===\n", feedID, timestamp, content)
+	if err := s.Append(UserFeedLog, entry); err != nil {
+		return err
+	}
+	if err := s.Write(FeedIDFile, strconv.Itoa(feedID+1)+"\n"); err != nil {
+		return err
+	}
+	trailers := &Trailers{Operation: OpFeed, FeedID: feedID}
+	return s.Commit(trailers.Message(fmt.Sprintf("Record Feed #%d", feedID)))
+}
+
+// GetSyncedCommitID reads the last synced commit ID from the store
+func GetSyncedCommitID(s MetaStore) (string, error) {
+	content, err := s.Read(SyncStateFile)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil // Never synced
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(content), nil
+}
+
+// SetSyncedCommitID saves the synced commit ID to the store
+func SetSyncedCommitID(s MetaStore, commitID string) error {
+	if err := s.Write(SyncStateFile, commitID+"\n"); err != nil {
+		return err
+	}
+	trailers := &Trailers{Operation: OpSync, CodeCommit: commitID}
+	return s.Commit(trailers.Message(fmt.Sprintf("Sync META to %s", commitID)))
+}
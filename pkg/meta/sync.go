@@ -0,0 +1,251 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SyncRange describes the code changes between the last synced commit and
+// the commit being synced. After a rebase or force-push the last synced
+// commit is no longer an ancestor of HEAD; the range then starts at the
+// merge base of both lineages and tells rewritten commits from new ones.
+type SyncRange struct {
+	// From is the last synced commit, empty on the first sync
+	From string
+	// To is the commit being synced
+	To string
+	// Base is where the old and new lineage meet. It equals From when the
+	// history was not rewritten, and is empty when no common commit exists.
+	Base string
+	// Rewritten reports whether From is not an ancestor of To
+	Rewritten bool
+	// Lost is the recorded synced commit when it no longer exists in the
+	// repository; From then falls back to an older synced commit
+	Lost string
+	// Added are the commits in Base..To without a counterpart in the old
+	// lineage, oldest first
+	Added []string
+	// Rewrites pair the commits in Base..To with the commit in Base..From
+	// carrying the same patch, for commits that were only rewritten
+	Rewrites []Rewrite
+	// Dropped are the commits in Base..From without a counterpart in the
+	// new lineage, oldest first
+	Dropped []string
+}
+
+// Rewrite is a commit of the old lineage and its rewritten counterpart
+type Rewrite struct {
+	Old string
+	New string
+}
+
+// UpToDate reports whether there is nothing to sync
+func (r *SyncRange) UpToDate() bool {
+	return r.From != "" && r.From == r.To && r.Lost == ""
+}
+
+// ComputeSyncRange returns the range to sync from the store's sync state
+// to commit to. A synced commit that no longer exists is replaced with the
+// newest earlier synced commit that does.
+func ComputeSyncRange(ctx context.Context, s MetaStore, to string) (*SyncRange, error) {
+	from, err := GetSyncedCommitID(s)
+	if err != nil {
+		return nil, err
+	}
+	r := &SyncRange{From: from, To: to}
+	if from == "" {
+		return r, nil
+	}
+
+	if !CommitExists(ctx, from) {
+		r.Lost = from
+		r.From, err = lastExistingSyncedCommit(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		if r.From == "" {
+			return r, nil
+		}
+	}
+	if r.From == to {
+		r.Base = to
+		return r, nil
+	}
+
+	base, err := runGitTrimmed(ctx, "", "merge-base", r.From, to)
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		base, err = "", nil // Unrelated histories
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Base = base
+	r.Rewritten = base != r.From
+
+	if !r.Rewritten {
+		r.Added, err = revList(ctx, "--reverse", r.From+".."+to)
+		return r, err
+	}
+	return r, r.matchRewritten(ctx)
+}
+
+// lastExistingSyncedCommit returns the newest synced commit recorded in the
+// store's history that still exists, or "" if none does
+func lastExistingSyncedCommit(ctx context.Context, s MetaStore) (string, error) {
+	revisions, err := s.History(SyncStateFile)
+	if err != nil {
+		return "", err
+	}
+	for _, rev := range revisions {
+		if rev.Trailers == nil || rev.Trailers.Operation != OpSync {
+			continue
+		}
+		if CommitExists(ctx, rev.Trailers.CodeCommit) {
+			return rev.Trailers.CodeCommit, nil
+		}
+	}
+	return "", nil
+}
+
+// matchRewritten pairs the commits of the old and new lineage by patch ID,
+// so a rebased commit is reported as rewritten instead of dropped and added
+func (r *SyncRange) matchRewritten(ctx context.Context) error {
+	oldRange, newRange := r.From, r.To
+	if r.Base != "" {
+		oldRange, newRange = r.Base+".."+r.From, r.Base+".."+r.To
+	}
+
+	oldCommits, err := revList(ctx, "--reverse", oldRange)
+	if err != nil {
+		return err
+	}
+	newCommits, err := revList(ctx, "--reverse", newRange)
+	if err != nil {
+		return err
+	}
+	oldPatches, err := patchIDs(ctx, oldRange)
+	if err != nil {
+		return err
+	}
+	newPatches, err := patchIDs(ctx, newRange)
+	if err != nil {
+		return err
+	}
+
+	byPatch := make(map[string]string)
+	for _, c := range oldCommits {
+		if id := oldPatches[c]; id != "" {
+			byPatch[id] = c
+		}
+	}
+	matched := make(map[string]bool)
+	for _, c := range newCommits {
+		if old, ok := byPatch[newPatches[c]]; ok && newPatches[c] != "" && !matched[old] {
+			r.Rewrites = append(r.Rewrites, Rewrite{Old: old, New: c})
+			matched[old] = true
+			continue
+		}
+		r.Added = append(r.Added, c)
+	}
+	for _, c := range oldCommits {
+		if !matched[c] {
+			r.Dropped = append(r.Dropped, c)
+		}
+	}
+	return nil
+}
+
+// patchIDs returns the stable patch ID of every non-merge commit in a range.
+// Commits without changes have no patch ID.
+func patchIDs(ctx context.Context, revRange string) (map[string]string, error) {
+	log, err := runGit(ctx, "", "log", "-p", "--no-merges", "--format=commit %H", revRange)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGit(ctx, log, "patch-id", "--stable")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			ids[fields[1]] = fields[0]
+		}
+	}
+	return ids, nil
+}
+
+// Describe explains the range to the sync agent
+func (r *SyncRange) Describe() string {
+	var b strings.Builder
+	switch {
+	case r.From == "" && r.Lost != "":
+		fmt.Fprintf(&b, "The last synced commit %s no longer exists and no earlier synced commit survives. Treat this as a first sync of %s.\n", r.Lost, r.To)
+		return b.String()
+	case r.From == "":
+		fmt.Fprintf(&b, "This is the first sync. Analyze the current state at %s.\n", r.To)
+		return b.String()
+	}
+
+	if r.Lost != "" {
+		fmt.Fprintf(&b, "The last synced commit %s no longer exists; syncing from the earlier synced commit %s instead.\n", r.Lost, r.From)
+	}
+	if !r.Rewritten {
+		fmt.Fprintf(&b, "Sync the changes in %s..%s.\n", r.From, r.To)
+		writeCommitList(&b, "New commits", r.Added)
+		return b.String()
+	}
+
+	if r.Base == "" {
+		fmt.Fprintf(&b, "History was rewritten: %s and %s share no commit. Do not use %s..%s.\n", r.From, r.To, r.From, r.To)
+	} else {
+		fmt.Fprintf(&b, "History was rewritten (rebase or force-push): %s is not an ancestor of %s. Their merge base is %s; do not use %s..%s.\n",
+			r.From, r.To, r.Base, r.From, r.To)
+	}
+	fmt.Fprintf(&b, "META describes the old lineage. Compare the code at %s with %s.\n", r.From, r.To)
+
+	var rewritten []string
+	for _, rw := range r.Rewrites {
+		rewritten = append(rewritten, fmt.Sprintf("%s (was %s)", rw.New, rw.Old))
+	}
+	writeCommitList(&b, "Rewritten commits, same change under a new ID", rewritten)
+	writeCommitList(&b, "New commits", r.Added)
+	writeCommitList(&b, "Dropped commits, no longer in the history; remove META facts that rely only on them", r.Dropped)
+	return b.String()
+}
+
+// writeCommitList appends a titled list of commits, if there are any
+func writeCommitList(b *strings.Builder, title string, commits []string) {
+	if len(commits) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", title)
+	for _, c := range commits {
+		fmt.Fprintf(b, "- %s\n", c)
+	}
+}
+
+// RecordSync saves To as the synced commit. The commit records the previous
+// synced commit and, for rewritten history, the merge base, so both
+// lineages stay visible in the shadow history.
+func RecordSync(s MetaStore, r *SyncRange) error {
+	if err := s.Write(SyncStateFile, r.To+"\n"); err != nil {
+		return err
+	}
+
+	trailers := &Trailers{Operation: OpSync, CodeCommit: r.To, PreviousCommit: r.From}
+	subject := fmt.Sprintf("Sync META to %s", r.To)
+	if r.Rewritten {
+		trailers.MergeBase = r.Base
+		subject = fmt.Sprintf("Sync META to rewritten history %s", r.To)
+	}
+	if r.Lost != "" {
+		trailers.PreviousCommit = r.Lost
+	}
+	return s.Commit(trailers.Message(subject))
+}
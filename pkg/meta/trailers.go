@@ -0,0 +1,198 @@
+package meta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Trailer keys carried by META commits
+const (
+	TrailerOperation  = "LM-Operation"
+	TrailerFeedID     = "LM-Feed-ID"
+	TrailerSkill      = "LM-Skill"
+	TrailerCodeCommit = "LM-Code-Commit"
+	TrailerItem       = "LM-Item"
+	TrailerSession    = "LM-Session"
+	// TrailerPreviousCommit and TrailerMergeBase record the old lineage
+	// on sync commits
+	TrailerPreviousCommit = "LM-Previous-Code-Commit"
+	TrailerMergeBase      = "LM-Merge-Base"
+)
+
+// Operations recorded in the LM-Operation trailer
+const (
+	OpInit    = "init"
+	OpFork    = "fork"
+	OpFeed    = "feed"
+	OpSync    = "sync"
+	OpMerge   = "merge"
+	OpMigrate = "migrate"
+	OpUpdate  = "update"
+)
+
+// SessionEnv passes the session ID of an lm invocation to the agent it
+// runs, so commits made by skills carry the same LM-Session trailer
+const SessionEnv = "LM_SESSION"
+
+// Trailers are the machine-readable fields of a META commit message
+type Trailers struct {
+	// Operation is what produced the commit, e.g. OpFeed or a skill's operation
+	Operation string
+	// FeedID is the feed recorded or processed by the commit, 0 if none
+	FeedID int
+	// Skill is the skill that made the commit, if any
+	Skill string
+	// CodeCommit is the code commit HEAD pointed to when the commit was made
+	CodeCommit string
+	// Items are the IDs of the items the commit touched
+	Items []string
+	// Session identifies the lm invocation the commit belongs to
+	Session string
+	// PreviousCommit is the code commit synced before, on sync commits
+	PreviousCommit string
+	// MergeBase is where the previous and new lineage meet, on sync
+	// commits after history was rewritten
+	MergeBase string
+}
+
+// Message returns the commit message for subject with the trailers appended
+func (t *Trailers) Message(subject string) string {
+	return t.MessageWithBody(subject, "")
+}
+
+// MessageWithBody returns the commit message for subject with body as its
+// description and the trailers appended after it
+func (t *Trailers) MessageWithBody(subject, body string) string {
+	if body = strings.TrimSpace(body); body != "" {
+		subject += "\n\n" + body
+	}
+	var lines []string
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	add(TrailerOperation, t.Operation)
+	if t.FeedID > 0 {
+		add(TrailerFeedID, strconv.Itoa(t.FeedID))
+	}
+	add(TrailerSkill, t.Skill)
+	add(TrailerCodeCommit, t.CodeCommit)
+	for _, item := range t.Items {
+		add(TrailerItem, item)
+	}
+	add(TrailerSession, t.Session)
+	add(TrailerPreviousCommit, t.PreviousCommit)
+	add(TrailerMergeBase, t.MergeBase)
+
+	if len(lines) == 0 {
+		return subject
+	}
+	return subject + "\n\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// ParseCommitMessage splits a commit message into its subject and the LM
+// trailers of its last paragraph. Messages without trailers yield empty Trailers.
+func ParseCommitMessage(message string) (string, *Trailers) {
+	message = strings.TrimRight(message, "\n")
+	subject, body, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(subject), ParseTrailers(body)
+}
+
+// ParseTrailers reads the LM trailers from the last paragraph of a commit
+// message body. Unknown keys and malformed lines are ignored.
+func ParseTrailers(body string) *Trailers {
+	t := &Trailers{}
+	paragraphs := strings.Split(strings.TrimSpace(body), "\n\n")
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case TrailerOperation:
+			t.Operation = value
+		case TrailerFeedID:
+			t.FeedID, _ = strconv.Atoi(value)
+		case TrailerSkill:
+			t.Skill = value
+		case TrailerCodeCommit:
+			t.CodeCommit = value
+		case TrailerItem:
+			t.Items = append(t.Items, value)
+		case TrailerSession:
+			t.Session = value
+		case TrailerPreviousCommit:
+			t.PreviousCommit = value
+		case TrailerMergeBase:
+			t.MergeBase = value
+		}
+	}
+	return t
+}
+
+// splitBody returns the body of a commit message between its subject and
+// its trailer block
+func splitBody(message string) string {
+	_, body, _ := strings.Cut(strings.TrimRight(message, "\n"), "\n")
+	paragraphs := strings.Split(strings.TrimSpace(body), "\n\n")
+	if isTrailerBlock(paragraphs[len(paragraphs)-1]) {
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// isTrailerBlock reports whether every line of a paragraph is an LM trailer
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		key, _, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(strings.TrimSpace(key), "LM-") {
+			return false
+		}
+	}
+	return true
+}
+
+// completeMessage fills in the trailers every META commit carries: the
+// operation, defaulting to OpUpdate, the current code commit and the
+// session. The body of the message is kept.
+func completeMessage(ctx context.Context, message string) string {
+	subject, t := ParseCommitMessage(message)
+	if t.Operation == "" {
+		t.Operation = OpUpdate
+	}
+	if t.CodeCommit == "" {
+		t.CodeCommit, _ = GetCurrentCommitID(ctx)
+	}
+	if t.Session == "" {
+		t.Session = SessionID()
+	}
+	return t.MessageWithBody(subject, splitBody(message))
+}
+
+var sessionID string
+
+// SessionID returns the ID of the current lm session: the LM_SESSION
+// environment variable when set by a parent lm process, or an ID
+// generated once per process
+func SessionID() string {
+	if sessionID != "" {
+		return sessionID
+	}
+	if sessionID = os.Getenv(SessionEnv); sessionID != "" {
+		return sessionID
+	}
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		sessionID = fmt.Sprintf("pid-%d", os.Getpid())
+	} else {
+		sessionID = hex.EncodeToString(buf)
+	}
+	return sessionID
+}
@@ -0,0 +1,88 @@
+package meta
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTrailersRoundTrip(t *testing.T) {
+	want := &Trailers{
+		Operation:  OpFeed,
+		FeedID:     7,
+		Skill:      "laddermoon-feed",
+		CodeCommit: "abc123",
+		Items:      []string{"question-001", "question-002"},
+		Session:    "s1",
+	}
+	subject, got := ParseCommitMessage(want.Message("Record Feed #7"))
+	if subject != "Record Feed #7" {
+		t.Errorf("subject = %q, want %q", subject, "Record Feed #7")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("trailers = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompleteMessage(t *testing.T) {
+	// Every message names its code commit and session, so completeMessage
+	// doesn't ask git for them
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "subject only",
+			message: "Update META\n\nLM-Code-Commit: abc\nLM-Session: s1",
+			want:    "Update META\n\nLM-Operation: update\nLM-Code-Commit: abc\nLM-Session: s1\n",
+		},
+		{
+			name:    "body kept",
+			message: "Update META\n\nWhy it changed.\n\nNote: more detail\n\nLM-Code-Commit: abc\nLM-Session: s1\n",
+			want:    "Update META\n\nWhy it changed.\n\nNote: more detail\n\nLM-Operation: update\nLM-Code-Commit: abc\nLM-Session: s1\n",
+		},
+		{
+			name:    "trailers kept",
+			message: "Record Feed #2\n\nLM-Operation: feed\nLM-Feed-ID: 2\nLM-Code-Commit: abc\nLM-Session: s1\n",
+			want:    "Record Feed #2\n\nLM-Operation: feed\nLM-Feed-ID: 2\nLM-Code-Commit: abc\nLM-Session: s1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := completeMessage(context.Background(), tt.message); got != tt.want {
+				t.Errorf("completeMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreCommitKeepsBody(t *testing.T) {
+	stores := map[string]func(t *testing.T) MetaStore{
+		"memory": func(t *testing.T) MetaStore { return NewMemStore(context.Background()) },
+		"shadow": func(t *testing.T) MetaStore { return setupTestRepo(t) },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := open(t)
+			s.Write(MetaFileName, "Uses PostgreSQL\n")
+			message := (&Trailers{Operation: OpUpdate, Skill: "laddermoon-sync"}).
+				MessageWithBody("Update META", "Why it changed.\n\nNote: more detail")
+			if err := s.Commit(message); err != nil {
+				t.Fatal(err)
+			}
+
+			history, err := s.History(MetaFileName)
+			if err != nil || len(history) == 0 {
+				t.Fatalf("History() = %v, %v", history, err)
+			}
+			rev := history[0]
+			if rev.Body != "Why it changed.\n\nNote: more detail" {
+				t.Errorf("body = %q, want the description kept", rev.Body)
+			}
+			if rev.Trailers.Operation != OpUpdate || rev.Trailers.Skill != "laddermoon-sync" {
+				t.Errorf("trailers = %+v, want the update by laddermoon-sync", rev.Trailers)
+			}
+		})
+	}
+}
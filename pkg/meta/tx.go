@@ -0,0 +1,152 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ErrTxDone is returned when a transaction is used after Commit
+var ErrTxDone = errors.New("META transaction already committed")
+
+type txOpKind int
+
+const (
+	txWrite txOpKind = iota
+	txAppend
+	txDelete
+)
+
+// txOp is a single staged change of a transaction
+type txOp struct {
+	kind     txOpKind
+	filename string
+	content  string
+}
+
+// Tx stages several file changes for the current branch's META directory
+// and lands them as one commit on the shadow branch. Like a database
+// transaction it is bound to the context it was started with: once the
+// context is done, Commit discards the staged changes instead of landing them.
+type Tx struct {
+	ctx       context.Context
+	branch    string
+	branchDir string
+	ops       []txOp
+	done      bool
+}
+
+// Begin starts a META transaction for the current branch
+func Begin(ctx context.Context) (*Tx, error) {
+	if !IsInitialized(ctx) {
+		return nil, ErrNotInitialized
+	}
+
+	branch, err := GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{ctx: ctx, branch: branch, branchDir: getBranchMetaDir(branch)}, nil
+}
+
+// Branch returns the git branch whose META directory the transaction writes to
+func (tx *Tx) Branch() string {
+	return tx.branch
+}
+
+// WriteFile stages overwriting a file with content
+func (tx *Tx) WriteFile(filename, content string) {
+	tx.ops = append(tx.ops, txOp{kind: txWrite, filename: filename, content: content})
+}
+
+// AppendToFile stages appending content to a file, creating it if needed
+func (tx *Tx) AppendToFile(filename, content string) {
+	tx.ops = append(tx.ops, txOp{kind: txAppend, filename: filename, content: content})
+}
+
+// DeleteFile stages removing a file
+func (tx *Tx) DeleteFile(filename string) {
+	tx.ops = append(tx.ops, txOp{kind: txDelete, filename: filename})
+}
+
+// Empty reports whether nothing has been staged
+func (tx *Tx) Empty() bool {
+	return len(tx.ops) == 0
+}
+
+// Commit lands all staged changes as a single shadow branch commit.
+// Staged operations are applied in order; appends see earlier writes
+// of the same transaction.
+func (tx *Tx) Commit(message string) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if err := tx.ctx.Err(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	tx.done = true
+
+	if tx.Empty() {
+		return nil
+	}
+
+	return commitShadow(tx.ctx, message, func(parent string) (map[string]*string, error) {
+		staged, err := applyOps(tx.ops, func(filename string) (string, error) {
+			content, _, err := readBlob(tx.ctx, parent, path.Join(tx.branchDir, filename))
+			return content, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		changes := make(map[string]*string, len(staged))
+		for filename, content := range staged {
+			changes[path.Join(tx.branchDir, filename)] = content
+		}
+		if err := registerBranch(tx.ctx, parent, changes, tx.branch, tx.branchDir); err != nil {
+			return nil, err
+		}
+		return changes, nil
+	})
+}
+
+// Rollback discards all staged changes. It does nothing after Commit.
+func (tx *Tx) Rollback() {
+	tx.ops = nil
+	tx.done = true
+}
+
+// applyOps replays staged operations in order and returns the resulting
+// content of every touched file, with nil marking a deleted file.
+// read returns the committed content of a file that hasn't been touched
+// yet, "" for a missing one. A failed read fails the whole replay, so an
+// append never replaces a file it couldn't read.
+func applyOps(ops []txOp, read func(filename string) (string, error)) (map[string]*string, error) {
+	changes := make(map[string]*string)
+	for _, op := range ops {
+		switch op.kind {
+		case txWrite:
+			content := op.content
+			changes[op.filename] = &content
+		case txAppend:
+			existing, staged := changes[op.filename]
+			var content string
+			if staged && existing != nil {
+				content = *existing
+			} else if !staged {
+				committed, err := read(op.filename)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", op.filename, err)
+				}
+				content = committed
+			}
+			content += op.content
+			changes[op.filename] = &content
+		case txDelete:
+			changes[op.filename] = nil
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,124 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrWorktreeConflict is returned when a file changed in a worktree was
+// also changed on the shadow branch since the worktree was checked out
+var ErrWorktreeConflict = errors.New("META changed since the worktree was checked out")
+
+// CommitWorktree commits the changes made to the branch directory in a
+// worktree of the shadow branch. Skills edit META in such a worktree and
+// commit through here instead of running git commit, so their changes
+// land with the same compare-and-swap as every other META commit rather
+// than moving the branch over concurrent commits.
+//
+// The changes are taken against the commit the worktree was checked out
+// at, whether they are committed in the worktree or not, and applied onto
+// the current tip. Changes to other branches' directories are ignored.
+// ErrWorktreeConflict is returned, and nothing committed, when one of the
+// changed files also changed on the tip in the meantime. It returns the
+// changed paths relative to the branch directory.
+func (s *ShadowStore) CommitWorktree(dir, message string) ([]string, error) {
+	if !IsInitialized(s.ctx) {
+		return nil, ErrNotInitialized
+	}
+
+	// Staging makes new files part of the worktree's tree
+	if _, err := runGit(s.ctx, "", "-C", dir, "add", "--all"); err != nil {
+		return nil, err
+	}
+	tree, err := runGitTrimmed(s.ctx, "", "-C", dir, "write-tree")
+	if err != nil {
+		return nil, err
+	}
+	head, err := runGitTrimmed(s.ctx, "", "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	base, err := runGitTrimmed(s.ctx, "", "merge-base", head, shadowRef)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a worktree of %s: %w", dir, BranchName, err)
+	}
+
+	before, err := snapshotDir(s.ctx, base, s.branchDir)
+	if err != nil {
+		return nil, err
+	}
+	after, err := snapshotDir(s.ctx, tree, s.branchDir)
+	if err != nil {
+		return nil, err
+	}
+	changed := changedBlobs(before.blobs, after.blobs)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	r, err := reader(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(map[string]*string, len(changed))
+	for _, rel := range changed {
+		full := path.Join(s.branchDir, rel)
+		id, ok := after.blobs[rel]
+		if !ok {
+			changes[full] = nil
+			continue
+		}
+		content, found, err := r.Blob(id)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, corrupt(full, "blob %s is missing", id)
+		}
+		changes[full] = &content
+	}
+
+	subject, trailers := ParseCommitMessage(message)
+	message = trailers.MessageWithBody(fmt.Sprintf("%s for branch %s", subject, s.branch), splitBody(message))
+	err = commitShadow(s.ctx, message, func(parent string) (map[string]*string, error) {
+		current, err := snapshotDir(s.ctx, parent, s.branchDir)
+		if err != nil {
+			return nil, err
+		}
+		var moved []string
+		for _, rel := range changed {
+			if before.blobs[rel] != current.blobs[rel] && after.blobs[rel] != current.blobs[rel] {
+				moved = append(moved, rel)
+			}
+		}
+		if len(moved) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrWorktreeConflict, strings.Join(moved, ", "))
+		}
+		return changes, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// changedBlobs returns the sorted paths whose blob differs between two
+// listings, including paths that exist in only one of them
+func changedBlobs(before, after map[string]string) []string {
+	var changed []string
+	for p, id := range after {
+		if before[p] != id {
+			changed = append(changed, p)
+		}
+	}
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,95 @@
+package meta
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommitWorktree(t *testing.T) {
+	store := setupTestRepo(t)
+	store.Write(MetaFileName, "Uses PostgreSQL\n")
+	store.Write("Questions/question-001-db.md", "# Question: Which DB?\n")
+	if err := store.Commit("Seed META"); err != nil {
+		t.Fatal(err)
+	}
+
+	// addWorktree checks out the shadow branch the way skills do
+	addWorktree := func(name string) string {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), name)
+		if out, err := exec.Command("git", "worktree", "add", "-q", "--detach", dir, BranchName).CombinedOutput(); err != nil {
+			t.Fatalf("git worktree add: %v: %s", err, out)
+		}
+		return filepath.Join(dir, store.branchDir)
+	}
+	write := func(p, content string) {
+		t.Helper()
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second := addWorktree("first"), addWorktree("second")
+	write(filepath.Join(first, MetaFileName), "Uses PostgreSQL 16 [Feed #1]\n")
+	write(filepath.Join(first, "Issues", "issue-001-slow.md"), "# Issue: Slow\n")
+	os.Remove(filepath.Join(first, "Questions", "question-001-db.md"))
+
+	// Another writer commits while the skill works
+	store.Write("Issues/issue-002-flaky.md", "# Issue: Flaky\n")
+	if err := store.Commit("Add issue"); err != nil {
+		t.Fatal(err)
+	}
+
+	message := (&Trailers{Operation: OpFeed, Skill: "laddermoon-feed", FeedID: 1}).Message("Feed #1: Pin the version")
+	changed, err := store.CommitWorktree(filepath.Dir(first), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Issues/issue-001-slow.md", MetaFileName, "Questions/question-001-db.md"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("CommitWorktree() = %v, want %v", changed, want)
+	}
+
+	files, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"Issues/issue-001-slow.md", "Issues/issue-002-flaky.md"} {
+		if !hasFile(files, f) {
+			t.Errorf("%s is missing after the worktree commit, files: %v", f, files)
+		}
+	}
+	if hasFile(files, "Questions/question-001-db.md") {
+		t.Error("the file deleted in the worktree is still there")
+	}
+	history, err := store.History(MetaFileName)
+	if err != nil || len(history) == 0 {
+		t.Fatalf("History() = %v, %v", history, err)
+	}
+	if tr := history[0].Trailers; tr.Operation != OpFeed || tr.FeedID != 1 || tr.Session == "" {
+		t.Errorf("trailers = %+v, want the feed with a session", tr)
+	}
+
+	// The second worktree predates the first commit and edits the same file
+	write(filepath.Join(second, MetaFileName), "Uses MySQL\n")
+	if _, err := store.CommitWorktree(filepath.Dir(second), "Feed #2: Switch database"); !errors.Is(err, ErrWorktreeConflict) {
+		t.Fatalf("CommitWorktree() error = %v, want ErrWorktreeConflict", err)
+	}
+	if content, _ := store.Read(MetaFileName); !strings.Contains(content, "PostgreSQL 16") {
+		t.Errorf("META.md = %q, want the first worktree's version kept", content)
+	}
+}
+
+func hasFile(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}